@@ -0,0 +1,43 @@
+package main
+
+import (
+	"io"
+	"strings"
+)
+
+// stripEscapes removes the ANSI escape sequences from s.
+func stripEscapes(s string) string {
+	var buffer strings.Builder
+	for len(s) > 0 {
+		if s[0] == '\x1B' && len(s) >= 2 && s[1] == '[' {
+			end := strings.IndexFunc(s[2:], func(c rune) bool {
+				return c >= '@' && c <= '~'
+			})
+			if end < 0 {
+				break
+			}
+			s = s[3+end:]
+			continue
+		}
+		end := strings.IndexByte(s[1:], '\x1B')
+		if end < 0 {
+			buffer.WriteString(s)
+			break
+		}
+		buffer.WriteString(s[:1+end])
+		s = s[1+end:]
+	}
+	return buffer.String()
+}
+
+// dumbWriter removes the escape sequences for the dumb terminals.
+type dumbWriter struct {
+	io.Writer
+}
+
+func (d dumbWriter) Write(p []byte) (int, error) {
+	if _, err := io.WriteString(d.Writer, stripEscapes(string(p))); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
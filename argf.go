@@ -10,25 +10,74 @@ import (
 type Argf struct {
 	args   []string
 	reader io.ReadCloser
+	// Size is the total size of the data. It is -1 when unknown.
+	Size int64
+	// Files are the files opened so far and where they start in the data.
+	Files []ArgfFile
+	// Compressed tells the first file was decompressed from gzip.
+	Compressed bool
+	read       int64
 }
 
-func NewArgf(args []string) (*Argf, error) {
-	if args == nil || len(args) < 1 {
-		return &Argf{args: nil, reader: ioutil.NopCloser(os.Stdin)}, nil
+type ArgfFile struct {
+	Name   string
+	Offset int64
+}
+
+// openArg opens the file or the URL and returns its size (-1 when unknown).
+// start is the offset of the file in the whole input.
+func openArg(fname string, start int64) (io.ReadCloser, int64, error) {
+	if isURL(fname) {
+		return openURL(fname)
 	}
-	fd, err := os.Open(args[0])
+	fd, err := os.Open(fname)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 	stat, err := fd.Stat()
 	if err != nil {
-		return nil, err
+		fd.Close()
+		return nil, 0, err
 	}
 	if stat.IsDir() {
-		return nil, fmt.Errorf("%s: can not read a directory", args[0])
+		fd.Close()
+		return nil, 0, fmt.Errorf("%s: can not read a directory", fname)
+	}
+	if *flagSkipErrors {
+		return &skipReader{File: fd, start: start, size: stat.Size()}, stat.Size(), nil
 	}
+	return fd, stat.Size(), nil
+}
 
-	return &Argf{args: args[1:], reader: fd}, nil
+func NewArgf(args []string) (*Argf, error) {
+	if args == nil || len(args) < 1 {
+		reader, err := decompress(ioutil.NopCloser(os.Stdin))
+		if err != nil {
+			return nil, err
+		}
+		return &Argf{args: nil, reader: reader, Size: -1}, nil
+	}
+	fd, size, err := openArg(args[0], 0)
+	if err != nil {
+		return nil, err
+	}
+	reader, err := decompress(fd)
+	if err != nil {
+		fd.Close()
+		return nil, fmt.Errorf("%s: %w", args[0], err)
+	}
+	d, ok := reader.(*decompressor)
+	compressed := ok && d.compressed
+	if len(args) != 1 || compressed {
+		size = -1
+	}
+	return &Argf{
+		args:       args[1:],
+		reader:     reader,
+		Size:       size,
+		Files:      []ArgfFile{{Name: args[0], Offset: 0}},
+		Compressed: compressed,
+	}, nil
 }
 
 func (this *Argf) Read(data []byte) (int, error) {
@@ -42,32 +91,43 @@ func (this *Argf) Read(data []byte) (int, error) {
 			if this.args != nil && len(this.args) >= 1 {
 				fname := this.args[0]
 				this.args = this.args[1:]
-				fd, err := os.Open(fname)
-				if err != nil {
-					return 0, fmt.Errorf("%s: %w", fname, err)
-				}
-				stat, err := fd.Stat()
+				fd, _, err := openArg(fname, this.read+int64(n))
 				if err != nil {
 					return 0, err
 				}
-				if stat.IsDir() {
-					return 0, fmt.Errorf("%s: can not read a directory", fname)
+				this.reader, err = decompress(fd)
+				if err != nil {
+					fd.Close()
+					return 0, fmt.Errorf("%s: %w", fname, err)
 				}
-				this.reader = fd
+				this.Files = append(this.Files, ArgfFile{Name: fname, Offset: this.read + int64(n)})
 			} else {
+				this.read += int64(n)
 				return n, io.EOF
 			}
+			err = nil
 		}
-		if n >= len(data) {
+		if err != nil || n >= len(data) {
 			break
 		}
 		var m int
 		m, err = this.reader.Read(data[n:])
 		n += m
 	}
+	this.read += int64(n)
 	return n, err
 }
 
+// FileAt returns the file which the offset of the data falls in.
+func (this *Argf) FileAt(offset int64) (ArgfFile, bool) {
+	for i := len(this.Files) - 1; i >= 0; i-- {
+		if this.Files[i].Offset <= offset {
+			return this.Files[i], true
+		}
+	}
+	return ArgfFile{}, false
+}
+
 func (this *Argf) Close() error {
 	var err error
 	if this.reader != nil {
@@ -44,4 +44,7 @@ func TestArgf(t *testing.T) {
 	if i != 6 {
 		t.Fatal("not read all data")
 	}
+	if f, ok := reader.FileAt(5); !ok || f.Name != args[2] || f.Offset != 4 {
+		t.Fatalf("FileAt(5) = %v,%v (expected %s at 4)", f, ok, args[2])
+	}
 }
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+func autoSaveName(fname string) string {
+	return fname + ".binview.autosave"
+}
+
+// AutoSaver writes the edited data to the file every -autosave seconds.
+type AutoSaver struct {
+	fname    string
+	interval time.Duration
+	// changes is Undo.Changes at the last save
+	changes int
+	// Last is the time of the last save. It is zero before the first one.
+	Last time.Time
+	next time.Time
+}
+
+func NewAutoSaver(fname string, seconds int) *AutoSaver {
+	interval := time.Duration(seconds) * time.Second
+	return &AutoSaver{
+		fname:    fname,
+		interval: interval,
+		next:     time.Now().Add(interval),
+	}
+}
+
+// Save writes the data when the interval has passed and the data has
+// changed since the last save. It reports whether the data was written.
+func (a *AutoSaver) Save(b *Buffer, u *Undo) (bool, error) {
+	now := time.Now()
+	if now.Before(a.next) {
+		return false, nil
+	}
+	a.next = now.Add(a.interval)
+	if u.Changes() == a.changes {
+		return false, nil
+	}
+	b.ReadAll()
+	// write to a temporary file and rename it not to leave the file half-written
+	fd, err := ioutil.TempFile(filepath.Dir(a.fname), filepath.Base(a.fname)+".*")
+	if err != nil {
+		return false, err
+	}
+	tmpName := fd.Name()
+	mode := os.FileMode(0644)
+	if stat, err := os.Stat(a.fname); err == nil {
+		mode = stat.Mode().Perm()
+	}
+	for _, s := range b.Slices {
+		if _, err := fd.Write(s); err != nil {
+			fd.Close()
+			os.Remove(tmpName)
+			return false, err
+		}
+	}
+	if err := fd.Close(); err != nil {
+		os.Remove(tmpName)
+		return false, err
+	}
+	if err := os.Chmod(tmpName, mode); err != nil {
+		os.Remove(tmpName)
+		return false, err
+	}
+	if err := os.Rename(tmpName, a.fname); err != nil {
+		os.Remove(tmpName)
+		return false, err
+	}
+	a.changes = u.Changes()
+	a.Last = now
+	return true, nil
+}
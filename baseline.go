@@ -0,0 +1,114 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// baseline is the contents of the file given with -base.
+// It is nil when no baseline is given.
+var baseline []byte
+
+// isDifferent reports whether the bytes at address differ from the baseline.
+// The bytes beyond the end of the baseline are different.
+func isDifferent(address int64, data []byte) bool {
+	if baseline == nil {
+		return false
+	}
+	for i, c := range data {
+		offset := address + int64(i)
+		if offset >= int64(len(baseline)) || baseline[offset] != c {
+			return true
+		}
+	}
+	return false
+}
+
+// compareAbove highlights the bytes different from the row above.
+var compareAbove = false
+
+// isChangedFromAbove reports whether the bytes at col differ from the
+// same column of prev which is the row above. prev is nil for the top row.
+func isChangedFromAbove(prev []byte, col int, data []byte) bool {
+	if !compareAbove || prev == nil {
+		return false
+	}
+	for i, c := range data {
+		if col+i >= len(prev) || prev[col+i] != c {
+			return true
+		}
+	}
+	return false
+}
+
+// diffMask returns the bits of c different from the baseline and
+// the row above. It is 0xFF when there is no byte to compare with.
+func diffMask(address int64, prev []byte, col int, c byte) byte {
+	var mask byte
+	if baseline != nil {
+		if address >= int64(len(baseline)) {
+			return 0xFF
+		}
+		mask |= baseline[address] ^ c
+	}
+	if compareAbove && prev != nil {
+		if col >= len(prev) {
+			return 0xFF
+		}
+		mask |= prev[col] ^ c
+	}
+	return mask
+}
+
+// diffNibbles draws c in hex coloring only the nibbles which have
+// the bits of mask. on and off are the colors of the other nibble.
+func diffNibbles(c, mask byte, on, off string) string {
+	const digits = "0123456789ABCDEF"
+	var buffer strings.Builder
+	for _, shift := range []uint{4, 0} {
+		if (mask>>shift)&0xF != 0 {
+			buffer.WriteString(DIFF_COLOR_ON)
+			buffer.WriteByte(digits[(c>>shift)&0xF])
+			buffer.WriteString(DIFF_COLOR_OFF)
+		} else {
+			buffer.WriteString(on)
+			buffer.WriteByte(digits[(c>>shift)&0xF])
+			buffer.WriteString(off)
+		}
+	}
+	return buffer.String()
+}
+
+// writePatch writes the bytes different from the baseline as the lines
+// "ADDRESS: OLD NEW" in hex. "--" stands for the byte beyond the end.
+// It returns the number of the different bytes.
+func writePatch(fname string, b *Buffer) (int, error) {
+	b.ReadAll()
+	fd, err := os.Create(fname)
+	if err != nil {
+		return 0, err
+	}
+	w := bufio.NewWriter(fd)
+	hex := func(data []byte, i int) string {
+		if i >= len(data) {
+			return "--"
+		}
+		return fmt.Sprintf("%02X", data[i])
+	}
+	current := b.Bytes()
+	count := 0
+	for i := 0; i < len(current) || i < len(baseline); i++ {
+		if i < len(current) && i < len(baseline) && current[i] == baseline[i] {
+			continue
+		}
+		fmt.Fprintf(w, "%08X: %s %s\n", homeAddress+int64(i), hex(baseline, i), hex(current, i))
+		count++
+	}
+	if err := w.Flush(); err != nil {
+		fd.Close()
+		return count, err
+	}
+	return count, fd.Close()
+}
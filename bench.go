@@ -0,0 +1,46 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// BENCH_FRAMES is the default number of the frames drawn by :bench
+const BENCH_FRAMES = 100
+
+// benchView measures the time to redraw the whole screen drawing it to
+// nowhere n times. It does not touch the screen cache.
+func (b *Buffer) benchView(startRow, frozen, csrpos, csrlin, w, h, n int) (time.Duration, error) {
+	saveCache := cache
+	defer func() { cache = saveCache }()
+
+	start := time.Now()
+	for i := 0; i < n; i++ {
+		cache = map[int]string{}
+		b.CursorY = startRow
+		if _, err := b.View(frozen, csrpos, csrlin, w, h, ioutil.Discard); err != nil {
+			return 0, err
+		}
+	}
+	return time.Since(start) / time.Duration(n), nil
+}
+
+// parseBenchCommand returns the number of the frames for ":bench [N]".
+// ok is false for the other commands.
+func parseBenchCommand(line string) (n int, ok bool, err error) {
+	field := strings.Fields(line)
+	if len(field) < 1 || field[0] != "bench" {
+		return 0, false, nil
+	}
+	if len(field) < 2 {
+		return BENCH_FRAMES, true, nil
+	}
+	n, err = strconv.Atoi(field[1])
+	if err != nil || n <= 0 {
+		return 0, true, fmt.Errorf("bench: %s: not a positive number", field[1])
+	}
+	return n, true, nil
+}
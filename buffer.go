@@ -14,16 +14,26 @@ func setLastByte(b []byte, lastByte byte) {
 	b[len(b)-1] = lastByte
 }
 
+// PROGRESS_STEP is the interval of bytes to call Buffer.Progress
+const PROGRESS_STEP = 4 * 1024 * 1024
+
+// readSize is the size of the blocks read from the input. The blocks are
+// divided into the rows of lineSize bytes afterward.
+var readSize = 64 * 1024
+
 type Buffer struct {
 	Slices [][]byte
 	*bufio.Reader
 	CursorY int
+	// Progress is called with the loaded size while reading much data when not nil.
+	Progress func(loaded int64)
+	reported int
 }
 
 func NewBuffer(r io.Reader) *Buffer {
 	return &Buffer{
 		Slices:  [][]byte{},
-		Reader:  bufio.NewReader(r),
+		Reader:  bufio.NewReaderSize(r, readSize),
 		CursorY: 0,
 	}
 }
@@ -43,15 +53,15 @@ func (b *Buffer) Rune(r, c int) (rune, int, int) {
 	currentPosInRune := 0
 	for !utf8.RuneStart(b.Byte(r, c)) {
 		c--
-		if c < 0 {
+		for c < 0 && r > 0 {
 			r--
-			if r < 0 {
-				r = 0
-				c = 0
-				break
-			}
 			c = len(b.Slices[r]) - 1
 		}
+		if c < 0 {
+			r = 0
+			c = 0
+			break
+		}
 		currentPosInRune++
 	}
 	bytes := make([]byte, 0, utf8.UTFMax)
@@ -79,6 +89,29 @@ func (b *Buffer) Rune(r, c int) (rune, int, int) {
 	return theRune, currentPosInRune, theLen
 }
 
+// Clamp returns the position nearest to (r, c) on the loaded data.
+// It is (0, 0) when no data is loaded.
+func (b *Buffer) Clamp(r, c int) (int, int) {
+	if r >= b.Count() {
+		r = b.Count() - 1
+		c = lineSize
+	}
+	for r > 0 && b.WidthAt(r) <= 0 {
+		r--
+		c = lineSize
+	}
+	if r < 0 || b.WidthAt(r) <= 0 {
+		return 0, 0
+	}
+	if c >= b.WidthAt(r) {
+		c = b.WidthAt(r) - 1
+	}
+	if c < 0 {
+		c = 0
+	}
+	return r, c
+}
+
 func (b *Buffer) SetLastLine(line []byte) {
 	b.Slices[len(b.Slices)-1] = line
 }
@@ -101,7 +134,7 @@ func (b *Buffer) Unshift(r int, appendByte byte) (deleteByte byte) {
 }
 
 func (b *Buffer) appendLine() error {
-	var slice1 [LINE_SIZE]byte
+	slice1 := make([]byte, lineSize)
 	n, err := b.Read(slice1[:])
 	if n > 0 {
 		b.Add(slice1[:n])
@@ -112,7 +145,7 @@ func (b *Buffer) appendLine() error {
 func (b *Buffer) appendTail() error {
 	last := b.LastLine()
 
-	slice1 := make([]byte, LINE_SIZE-len(last))
+	slice1 := make([]byte, lineSize-len(last))
 	n, err := b.Read(slice1)
 	if n > 0 {
 		last = append(last, slice1[:n]...)
@@ -121,14 +154,14 @@ func (b *Buffer) appendTail() error {
 	return err
 }
 
-func (b *Buffer) Fetch() ([]byte, int, error) {
+func (b *Buffer) Fetch() ([]byte, int64, error) {
 	if b.CursorY >= len(b.Slices) {
 		if b.Reader == nil {
-			return nil, b.CursorY * LINE_SIZE, io.EOF
+			return nil, int64(b.CursorY) * int64(lineSize), io.EOF
 		}
 		var err error
 		if b.Slices == nil || len(b.Slices) <= 0 ||
-			len(b.Slices[len(b.Slices)-1]) == LINE_SIZE {
+			len(b.Slices[len(b.Slices)-1]) == lineSize {
 			err = b.appendLine()
 		} else {
 			err = b.appendTail()
@@ -145,18 +178,43 @@ func (b *Buffer) Fetch() ([]byte, int, error) {
 	}
 	bin := b.Line(b.CursorY)
 	b.CursorY++
-	return bin, (b.CursorY - 1) * LINE_SIZE, nil
+	return bin, int64(b.CursorY-1) * int64(lineSize), nil
+}
+
+func (b *Buffer) progress() {
+	if b.Progress == nil {
+		return
+	}
+	if step := b.Len() / PROGRESS_STEP; step != b.reported {
+		b.reported = step
+		b.Progress(int64(b.Len()))
+	}
 }
 
 func (b *Buffer) ReadAll() {
 	if b.Reader == nil {
 		return
 	}
+	if len(b.Slices) > 0 && len(b.LastLine()) < lineSize {
+		last := b.LastLine()
+		tail := make([]byte, lineSize-len(last))
+		n, err := io.ReadFull(b.Reader, tail)
+		b.SetLastLine(append(last, tail[:n]...))
+		if err != nil {
+			b.Reader = nil
+			return
+		}
+	}
 	for {
-		var data [LINE_SIZE]byte
-		n, err := b.Read(data[:])
-		if n > 0 {
-			b.Add(data[:n])
+		b.progress()
+		block := make([]byte, readSize/lineSize*lineSize)
+		n, err := io.ReadFull(b.Reader, block)
+		for i := 0; i < n; i += lineSize {
+			end := i + lineSize
+			if end > n {
+				end = n
+			}
+			b.Add(block[i:end:end])
 		}
 		if err != nil {
 			b.Reader = nil
@@ -164,3 +222,75 @@ func (b *Buffer) ReadAll() {
 		}
 	}
 }
+
+func (b *Buffer) ReadUntil(row int) error {
+	for b.Count() <= row && b.Reader != nil {
+		b.progress()
+		b.CursorY = b.Count()
+		if _, _, err := b.Fetch(); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+func (b *Buffer) Len() int {
+	if len(b.Slices) <= 0 {
+		return 0
+	}
+	return (len(b.Slices)-1)*lineSize + len(b.LastLine())
+}
+
+func (b *Buffer) ByteAt(offset int) byte {
+	return b.Slices[offset/lineSize][offset%lineSize]
+}
+
+func (b *Buffer) SetByteAt(offset int, data byte) {
+	b.Slices[offset/lineSize][offset%lineSize] = data
+}
+
+func (b *Buffer) Bytes() []byte {
+	all := make([]byte, 0, b.Len())
+	for _, s := range b.Slices {
+		all = append(all, s...)
+	}
+	return all
+}
+
+func (b *Buffer) SetBytes(all []byte) {
+	b.Slices = b.Slices[:0]
+	for len(all) > lineSize {
+		b.Add(all[:lineSize:lineSize])
+		all = all[lineSize:]
+	}
+	if len(all) > 0 {
+		b.Add(all)
+	}
+}
+
+// Splice replaces n bytes at offset with data.
+func (b *Buffer) Splice(offset, n int, data []byte) {
+	b.ReadAll()
+	if n == len(data) {
+		for i, c := range data {
+			b.SetByteAt(offset+i, c)
+		}
+		return
+	}
+	all := b.Bytes()
+	tmp := make([]byte, 0, len(all)-n+len(data))
+	tmp = append(tmp, all[:offset]...)
+	tmp = append(tmp, data...)
+	tmp = append(tmp, all[offset+n:]...)
+	b.SetBytes(tmp)
+}
+
+// SetLineSize changes the number of bytes per row and re-chunks the loaded data.
+func (b *Buffer) SetLineSize(size int) {
+	all := b.Bytes()
+	lineSize = size
+	b.SetBytes(all)
+}
@@ -0,0 +1,28 @@
+package main
+
+const (
+	CLASS_PRINT_COLOR_ON = "\x1B[32;40;22m"
+	CLASS_SPACE_COLOR_ON = "\x1B[37;40;2m"
+	CLASS_ZERO_COLOR_ON  = "\x1B[30;40;1m"
+	CLASS_FF_COLOR_ON    = "\x1B[31;40;22m"
+	CLASS_COLOR_OFF      = "\x1B[37;22m"
+)
+
+// byteClassColor colors the bytes by their classes instead of the positions.
+var byteClassColor = false
+
+// classColor returns the color for the class of c.
+// ok is false for the other bytes, which are colored as usual.
+func classColor(c byte) (on, off string, ok bool) {
+	switch {
+	case c == 0:
+		return CLASS_ZERO_COLOR_ON, CLASS_COLOR_OFF, true
+	case c == 0xFF:
+		return CLASS_FF_COLOR_ON, CLASS_COLOR_OFF, true
+	case c == ' ' || ('\t' <= c && c <= '\r'):
+		return CLASS_SPACE_COLOR_ON, CLASS_COLOR_OFF, true
+	case 0x21 <= c && c <= 0x7E:
+		return CLASS_PRINT_COLOR_ON, CLASS_COLOR_OFF, true
+	}
+	return "", "", false
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+)
+
+var charEscapes = map[byte]string{
+	0:    `\0`,
+	'\a': `\a`,
+	'\b': `\b`,
+	'\t': `\t`,
+	'\n': `\n`,
+	'\v': `\v`,
+	'\f': `\f`,
+	'\r': `\r`,
+}
+
+// charCell returns the byte as od -c does with \xNN for the other bytes.
+func charCell(c byte) string {
+	if s, ok := charEscapes[c]; ok {
+		return s
+	}
+	if c < ' ' || c >= 0x7F {
+		return fmt.Sprintf(`\x%02X`, c)
+	}
+	return string(rune(c))
+}
+
+// int8Cell returns the byte as the signed decimal for -mode int8.
+func int8Cell(c byte) string {
+	return strconv.Itoa(int(int8(c)))
+}
+
+// drawChars draws the row for -mode char and int8 instead of the hex and
+// text pane. cell converts each byte into the string of 4 columns at most.
+func drawChars(out io.Writer, address int64, cursorPos int, slice, prev []byte, cell func(byte) string) {
+	for i, s := range slice {
+		at := address + int64(swapIndex(i, len(slice)))
+		var on, off string
+		if i == cursorPos {
+			on = CURSOR_COLOR_ON
+			off = CURSOR_COLOR_OFF
+		} else if isSelected(at) {
+			on = SELECT_COLOR_ON
+			off = SELECT_COLOR_OFF
+		} else if isFound(at) {
+			on = FOUND_COLOR_ON
+			off = FOUND_COLOR_OFF
+		} else if color, ok := highlightColor(at); ok {
+			on = color
+			off = HIGHLIGHT_COLOR_OFF
+		} else if isDifferent(at, slice[i:i+1]) || isChangedFromAbove(prev, i, slice[i:i+1]) {
+			on = DIFF_COLOR_ON
+			off = DIFF_COLOR_OFF
+		} else if cOn, cOff, ok := classColor(s); byteClassColor && ok {
+			on = cOn
+			off = cOff
+		} else if i%2 == 0 {
+			on = CELL1_COLOR_ON
+			off = CELL1_COLOR_OFF
+		} else {
+			on = CELL2_COLOR_ON
+			off = CELL2_COLOR_OFF
+		}
+		text := cell(s)
+		if isUnreadable(at) {
+			text = "??"
+			if i != cursorPos {
+				on = UNREADABLE_COLOR_ON
+				off = UNREADABLE_COLOR_OFF
+			}
+		}
+		fmt.Fprintf(out, " %s%4s%s", on, text, off)
+	}
+	io.WriteString(out, ERASE_LINE)
+}
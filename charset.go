@@ -0,0 +1,54 @@
+package main
+
+import (
+	"fmt"
+	"unicode/utf8"
+)
+
+// charsets are the encodings of the text pane.
+var charsets = []string{"utf8", "latin1", "ascii"}
+
+var charset = "utf8"
+
+// previousCharset is the charset before the last change for the toggle.
+var previousCharset = ""
+
+func setCharset(name string) error {
+	for _, c := range charsets {
+		if c == name {
+			if name != charset {
+				previousCharset = charset
+				charset = name
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("%s: unknown charset (utf8, latin1 or ascii)", name)
+}
+
+// decodeChar returns the character at the top of slice to show on
+// the text pane and its length in bytes.
+// The characters which can not be shown are replaced with '.'.
+func decodeChar(slice []byte) (rune, int) {
+	c := rune(slice[0])
+	if c < ' ' || c == '\u007F' {
+		return '.', 1
+	}
+	if c < utf8.RuneSelf {
+		return c, 1
+	}
+	switch charset {
+	case "latin1":
+		if c < 0xA0 {
+			return '.', 1
+		}
+		return c, 1
+	case "ascii":
+		return '.', 1
+	}
+	c, length := utf8.DecodeRune(slice)
+	if c == utf8.RuneError {
+		return '.', 1
+	}
+	return c, length
+}
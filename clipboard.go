@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/atotto/clipboard"
+)
+
+func copyAddress(offset int) (string, error) {
+	text := fmt.Sprintf("0x%08X", homeAddress+int64(offset))
+	if err := clipboard.WriteAll(text); err != nil {
+		return "", err
+	}
+	return "copied the address " + text, nil
+}
+
+// copyValue copies the byte on the cursor or the selected bytes as hex.
+func copyValue(b *Buffer, start, end int) (string, error) {
+	var text string
+	if start == end {
+		text = fmt.Sprintf("0x%02X", b.ByteAt(start))
+	} else {
+		var buffer strings.Builder
+		for i := start; i <= end; i++ {
+			if i > start {
+				buffer.WriteByte(' ')
+			}
+			fmt.Fprintf(&buffer, "%02X", b.ByteAt(i))
+		}
+		text = buffer.String()
+	}
+	if err := clipboard.WriteAll(text); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("copied %d bytes: %s", end-start+1, text), nil
+}
+
+// copyRow copies the row as shown on the screen without the colors.
+func copyRow(b *Buffer, row int) (string, error) {
+	saveText, saveHex := hideTextPane, hideHexPane
+	hideTextPane, hideHexPane = false, false
+	var buffer strings.Builder
+	draw(&buffer, int64(row)*int64(lineSize), -1, -1, b.Line(row), nil)
+	hideTextPane, hideHexPane = saveText, saveHex
+
+	text := strings.TrimRight(stripEscapes(buffer.String()), " ")
+	if err := clipboard.WriteAll(text); err != nil {
+		return "", err
+	}
+	return "copied the row: " + text, nil
+}
+
+// pastedOffset parses the clipboard as the address for goto.
+// The digits without 0x are also read as hex like the debuggers show.
+func pastedOffset(b *Buffer, current int) (int, error) {
+	text, err := clipboard.ReadAll()
+	if err != nil {
+		return 0, err
+	}
+	text = strings.TrimSpace(text)
+	// only the bare hex digits pass ParseUint with the base 16
+	if n, err := strconv.ParseUint(text, 16, 64); err == nil {
+		return int(int64(n) - homeAddress), nil
+	}
+	if offset, err := parseAddress(b, text, current); err == nil {
+		return offset, nil
+	}
+	return 0, fmt.Errorf("%q: the clipboard is not an address", text)
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"unicode/utf8"
+)
+
+// CURSOR_LOG_AROUND is the number of the bytes before and after the cursor
+// written to the cursor log
+const CURSOR_LOG_AROUND = 8
+
+// CursorInfo is one line of the cursor log written with -cursor-log
+type CursorInfo struct {
+	Offset int64  `json:"offset"`
+	Byte   int    `json:"byte"`
+	Rune   string `json:"rune,omitempty"`
+	// Around are the bytes from AroundOffset including the cursor
+	AroundOffset int64 `json:"around_offset"`
+	Around       []int `json:"around"`
+}
+
+// CursorLog writes the context of the cursor as a JSON line whenever
+// the cursor moves so that the other tools can follow it.
+type CursorLog struct {
+	fd   *os.File
+	enc  *json.Encoder
+	last int
+}
+
+func OpenCursorLog(fname string) (*CursorLog, error) {
+	fd, err := os.OpenFile(fname, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0666)
+	if err != nil {
+		return nil, err
+	}
+	return &CursorLog{fd: fd, enc: json.NewEncoder(fd), last: -1}, nil
+}
+
+// Write writes the line unless the cursor is on the same offset as the last time.
+func (c *CursorLog) Write(b *Buffer, row, col int) error {
+	offset := row*lineSize + col
+	if offset == c.last || offset >= b.Len() {
+		return nil
+	}
+	c.last = offset
+	info := CursorInfo{
+		Offset: homeAddress + int64(offset),
+		Byte:   int(b.ByteAt(offset)),
+		Around: []int{},
+	}
+	if r, _, _ := b.Rune(row, col); r != utf8.RuneError {
+		info.Rune = string(r)
+	}
+	start := offset - CURSOR_LOG_AROUND
+	if start < 0 {
+		start = 0
+	}
+	end := offset + CURSOR_LOG_AROUND
+	if end >= b.Len() {
+		end = b.Len() - 1
+	}
+	info.AroundOffset = homeAddress + int64(start)
+	for i := start; i <= end; i++ {
+		info.Around = append(info.Around, int(b.ByteAt(i)))
+	}
+	return c.enc.Encode(&info)
+}
+
+func (c *CursorLog) Close() error {
+	return c.fd.Close()
+}
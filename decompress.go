@@ -0,0 +1,44 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"io"
+)
+
+var gzipMagic = []byte{0x1F, 0x8B}
+
+type decompressor struct {
+	io.Reader
+	closers    []io.Closer
+	compressed bool
+}
+
+func (d *decompressor) Close() error {
+	var err error
+	for _, c := range d.closers {
+		if err1 := c.Close(); err1 != nil && err == nil {
+			err = err1
+		}
+	}
+	return err
+}
+
+// decompress wraps r with the decompressor when r begins with the gzip magic.
+// Otherwise the contents of r are returned as they are.
+func decompress(r io.ReadCloser) (io.ReadCloser, error) {
+	if *flagRaw {
+		return r, nil
+	}
+	br := bufio.NewReader(r)
+	magic, _ := br.Peek(len(gzipMagic))
+	if !bytes.Equal(magic, gzipMagic) {
+		return &decompressor{Reader: br, closers: []io.Closer{r}}, nil
+	}
+	zr, err := gzip.NewReader(br)
+	if err != nil {
+		return nil, err
+	}
+	return &decompressor{Reader: zr, closers: []io.Closer{zr, r}, compressed: true}, nil
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Expectations are the bytes expected at the offsets to verify the patches
+type Expectations map[int]byte
+
+var expectations = Expectations{}
+
+// Set expects values at offset and the following offsets.
+// The empty values remove the expectation at offset.
+func (e Expectations) Set(offset int, values []byte) string {
+	if len(values) <= 0 {
+		if _, ok := e[offset]; !ok {
+			return fmt.Sprintf("0x%08X: nothing is expected", homeAddress+int64(offset))
+		}
+		delete(e, offset)
+		return fmt.Sprintf("0x%08X: removed the expectation", homeAddress+int64(offset))
+	}
+	for i, c := range values {
+		e[offset+i] = c
+	}
+	return fmt.Sprintf("expecting %d bytes from 0x%08X", len(values), homeAddress+int64(offset))
+}
+
+func (e Expectations) match(b *Buffer, offset int) bool {
+	return offset < b.Len() && b.ByteAt(offset) == e[offset]
+}
+
+// Status returns the result of the expectation at the cursor and the number
+// of the matching ones for the status line. It is empty when nothing is expected.
+func (e Expectations) Status(b *Buffer, cursor int) string {
+	if len(e) <= 0 {
+		return ""
+	}
+	var status strings.Builder
+	status.WriteString(" [EXPECT ")
+	if c, ok := e[cursor]; ok {
+		if e.match(b, cursor) {
+			fmt.Fprintf(&status, "0x%02X ok, ", c)
+		} else {
+			fmt.Fprintf(&status, "0x%02X NG, ", c)
+		}
+	}
+	pass := 0
+	for offset := range e {
+		if e.match(b, offset) {
+			pass++
+		}
+	}
+	fmt.Fprintf(&status, "%d/%d pass]", pass, len(e))
+	return status.String()
+}
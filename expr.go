@@ -0,0 +1,134 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// exprParser evaluates the expression typed for goto like
+// "0x400 + 4*16" or "@ + 32" where @ is the address of the cursor.
+type exprParser struct {
+	tokens []string
+	at     int64
+}
+
+func tokenizeExpr(s string) ([]string, error) {
+	tokens := []string{}
+	for len(s) > 0 {
+		c := rune(s[0])
+		switch {
+		case unicode.IsSpace(c):
+			s = s[1:]
+		case strings.ContainsRune("+-*()@", c):
+			tokens = append(tokens, s[:1])
+			s = s[1:]
+		case unicode.IsDigit(c):
+			end := strings.IndexFunc(s, func(r rune) bool {
+				return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_'
+			})
+			if end < 0 {
+				end = len(s)
+			}
+			tokens = append(tokens, s[:end])
+			s = s[end:]
+		default:
+			return nil, fmt.Errorf("%q: unexpected character", c)
+		}
+	}
+	return tokens, nil
+}
+
+func (p *exprParser) next() string {
+	if len(p.tokens) <= 0 {
+		return ""
+	}
+	return p.tokens[0]
+}
+
+func (p *exprParser) expr() (int64, error) {
+	value, err := p.term()
+	if err != nil {
+		return 0, err
+	}
+	for p.next() == "+" || p.next() == "-" {
+		op := p.next()
+		p.tokens = p.tokens[1:]
+		rhs, err := p.term()
+		if err != nil {
+			return 0, err
+		}
+		if op == "+" {
+			value += rhs
+		} else {
+			value -= rhs
+		}
+	}
+	return value, nil
+}
+
+func (p *exprParser) term() (int64, error) {
+	value, err := p.factor()
+	if err != nil {
+		return 0, err
+	}
+	for p.next() == "*" {
+		p.tokens = p.tokens[1:]
+		rhs, err := p.factor()
+		if err != nil {
+			return 0, err
+		}
+		value *= rhs
+	}
+	return value, nil
+}
+
+func (p *exprParser) factor() (int64, error) {
+	token := p.next()
+	if token == "" {
+		return 0, fmt.Errorf("unexpected end of the expression")
+	}
+	p.tokens = p.tokens[1:]
+	switch token {
+	case "@":
+		return p.at, nil
+	case "+":
+		return p.factor()
+	case "-":
+		value, err := p.factor()
+		return -value, err
+	case "(":
+		value, err := p.expr()
+		if err != nil {
+			return 0, err
+		}
+		if p.next() != ")" {
+			return 0, fmt.Errorf("missing )")
+		}
+		p.tokens = p.tokens[1:]
+		return value, nil
+	}
+	n, err := strconv.ParseUint(token, 0, 63)
+	if err != nil {
+		return 0, fmt.Errorf("%s: not a number", token)
+	}
+	return int64(n), nil
+}
+
+// evalExpr evaluates s with @ as at.
+func evalExpr(s string, at int64) (int64, error) {
+	tokens, err := tokenizeExpr(s)
+	if err != nil {
+		return 0, err
+	}
+	p := &exprParser{tokens: tokens, at: at}
+	value, err := p.expr()
+	if err != nil {
+		return 0, err
+	}
+	if len(p.tokens) > 0 {
+		return 0, fmt.Errorf("%s: unexpected token", p.tokens[0])
+	}
+	return value, nil
+}
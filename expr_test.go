@@ -0,0 +1,28 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestEvalExpr(t *testing.T) {
+	for source, expect := range map[string]int64{
+		"0x400 + 4*16":   0x440,
+		"@ + 32":         0x120,
+		"(@ - 0x10) * 2": 0x1E0,
+		"-4 + 10":        6,
+		"1_000":          1000,
+	} {
+		value, err := evalExpr(source, 0x100)
+		if err != nil {
+			t.Fatalf("%s: %s", source, err.Error())
+		}
+		if value != expect {
+			t.Fatalf("%s: %d != %d", source, value, expect)
+		}
+	}
+	for _, source := range []string{"", "1 +", "(1", "1 2", "0xZZ", "1 / 2"} {
+		if _, err := evalExpr(source, 0); err == nil {
+			t.Fatalf("%q: no error", source)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+func shellCommand(cmdline string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return exec.Command("cmd", "/c", cmdline)
+	}
+	return exec.Command("sh", "-c", cmdline)
+}
+
+// filterSelection replaces the selected range with the output of cmdline
+// which reads the selection from its standard input.
+func filterSelection(b *Buffer, cmdline string) (Edit, string, error) {
+	old := copyRange(b, selectStart, selectEnd)
+	var stdout, stderr bytes.Buffer
+	cmd := shellCommand(cmdline)
+	cmd.Stdin = bytes.NewReader(old)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return Edit{}, "", fmt.Errorf("%s: %s", cmdline, msg)
+		}
+		return Edit{}, "", fmt.Errorf("%s: %w", cmdline, err)
+	}
+	new := stdout.Bytes()
+	b.Splice(selectStart, len(old), new)
+	return Edit{Offset: selectStart, Old: old, New: new},
+		fmt.Sprintf("filtered %d bytes into %d bytes", len(old), len(new)), nil
+}
@@ -12,6 +12,9 @@ import (
 )
 
 func getline(out io.Writer, prompt string, defaultStr string) (string, error) {
+	if scriptedKeys != nil {
+		return scriptedLine(out, prompt, defaultStr)
+	}
 	editor := readline.Editor{
 		Writer:  out,
 		Default: defaultStr,
@@ -28,6 +31,9 @@ func getline(out io.Writer, prompt string, defaultStr string) (string, error) {
 }
 
 func getkey(tty1 *tty.TTY) (string, error) {
+	if scriptedKeys != nil {
+		return nextScriptedKey()
+	}
 	clean, err := tty1.Raw()
 	if err != nil {
 		return "", err
@@ -62,8 +68,12 @@ func getkey(tty1 *tty.TTY) (string, error) {
 	}
 }
 
-func yesNo(tty1 *tty.TTY, out io.Writer, message string) bool {
+func askKey(tty1 *tty.TTY, out io.Writer, message string) (string, error) {
 	fmt.Fprintf(out, "%s\r%s%s", _ANSI_YELLOW, message, ERASE_LINE)
-	ch, err := getkey(tty1)
+	return getkey(tty1)
+}
+
+func yesNo(tty1 *tty.TTY, out io.Writer, message string) bool {
+	ch, err := askKey(tty1, out, message)
 	return err == nil && ch == "y"
 }
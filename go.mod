@@ -3,9 +3,9 @@ module github.com/zetamatta/binview
 go 1.15
 
 require (
-	github.com/atotto/clipboard v0.1.4 // indirect
+	github.com/atotto/clipboard v0.1.4
 	github.com/mattn/go-colorable v0.1.8
-	github.com/mattn/go-isatty v0.0.13 // indirect
+	github.com/mattn/go-isatty v0.0.13
 	github.com/mattn/go-runewidth v0.0.13
 	github.com/mattn/go-tty v0.0.4-0.20201120140209-72ed86c4554d
 	github.com/zetamatta/go-readline-ny v0.4.13
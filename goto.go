@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// parseAddress parses the address typed for goto.
+// "$-N" means N bytes before the end of the data and
+// "+N" or "-N" with one number N means N bytes after or before the offset current.
+// The others and N after $- are the expressions evaluated by evalExpr,
+// where the leading sign is of the first term like "-4 + 10" (= 6).
+func parseAddress(b *Buffer, s string, current int) (int, error) {
+	s = strings.TrimSpace(s)
+	at := homeAddress + int64(current)
+	if strings.HasPrefix(s, "$") {
+		s = strings.TrimSpace(s[1:])
+		b.ReadAll()
+		if s == "" {
+			return b.Len() - 1, nil
+		}
+		if !strings.HasPrefix(s, "-") {
+			return 0, fmt.Errorf("$%s: only $-N is allowed", s)
+		}
+		n, err := evalExpr(s[1:], at)
+		if err != nil {
+			return 0, err
+		}
+		return b.Len() - int(n), nil
+	}
+	if strings.HasPrefix(s, "+") || strings.HasPrefix(s, "-") {
+		if tokens, err := tokenizeExpr(s[1:]); err == nil && len(tokens) == 1 && isDigitOf(tokens[0][0], 10) {
+			n, err := evalExpr(tokens[0], at)
+			if err != nil {
+				return 0, err
+			}
+			if s[0] == '-' {
+				n = -n
+			}
+			return intOffset(int64(current) + n)
+		}
+	}
+	n, err := evalExpr(s, at)
+	if err != nil {
+		return 0, err
+	}
+	return intOffset(n - homeAddress)
+}
+
+// seekOffset reads the data until offset and returns the cursor position
+// clamped in the data.
+func seekOffset(b *Buffer, offset int) (int, int, error) {
+	if offset < 0 {
+		offset = 0
+	}
+	row := offset / lineSize
+	if err := b.ReadUntil(row); err != nil {
+		return 0, 0, err
+	}
+	if b.Count() <= 0 {
+		return 0, 0, nil
+	}
+	if row >= b.Count() {
+		row = b.Count() - 1
+		return row, b.WidthAt(row) - 1, nil
+	}
+	col := offset % lineSize
+	if col >= b.WidthAt(row) {
+		col = b.WidthAt(row) - 1
+	}
+	return row, col, nil
+}
+
+// alignOffset returns the offset of the previous boundary, whose address is
+// the multiple of n at or before offset. When next is true, it returns the
+// next boundary after offset.
+func alignOffset(offset, n int, next bool) int {
+	address := homeAddress + int64(offset)
+	if next {
+		address = (address/int64(n) + 1) * int64(n)
+	} else {
+		address = address / int64(n) * int64(n)
+	}
+	return int(address - homeAddress)
+}
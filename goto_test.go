@@ -0,0 +1,27 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestParseAddress(t *testing.T) {
+	for source, expect := range map[string]int{
+		"0x40":         0x40,
+		"+0x10":        0x110,
+		"-16":          0xF0,
+		"- 16":         0xF0,
+		"-4 + 10":      6,
+		"+4 * 2":       8,
+		"@ - 4 + 10":   0x106,
+		"@ + 4*2":      0x108,
+		"-(@ - 0x110)": 0x10,
+	} {
+		offset, err := parseAddress(nil, source, 0x100)
+		if err != nil {
+			t.Fatalf("%s: %s", source, err.Error())
+		}
+		if offset != expect {
+			t.Fatalf("%s: 0x%X != 0x%X", source, offset, expect)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+)
+
+const MAX_HIGHLIGHTS = 9
+
+const HIGHLIGHT_COLOR_OFF = "\x1B[37;40m"
+
+var highlightColors = [...]string{
+	"\x1B[30;43;22m",
+	"\x1B[30;45;22m",
+	"\x1B[30;46;22m",
+	"\x1B[37;41;22m",
+	"\x1B[30;47;22m",
+}
+
+type Highlight struct {
+	Pattern []byte
+	Color   string
+	Enabled bool
+}
+
+// highlights are the patterns toggled with 1 to 9.
+var highlights []*Highlight
+
+// highlighted is the colors of the bytes in the row being drawn.
+var highlighted = map[int64]string{}
+
+func addHighlight(pattern []byte) (int, error) {
+	if len(highlights) >= MAX_HIGHLIGHTS {
+		return 0, fmt.Errorf("too many highlights (max %d)", MAX_HIGHLIGHTS)
+	}
+	if len(pattern) <= 0 {
+		return 0, errors.New("empty pattern")
+	}
+	highlights = append(highlights, &Highlight{
+		Pattern: pattern,
+		Color:   highlightColors[len(highlights)%len(highlightColors)],
+		Enabled: true,
+	})
+	return len(highlights), nil
+}
+
+// updateHighlights finds the enabled patterns overlapping n bytes from offset.
+func updateHighlights(b *Buffer, offset, n int) error {
+	highlighted = map[int64]string{}
+	maxLen := 0
+	for _, h := range highlights {
+		if h.Enabled && len(h.Pattern) > maxLen {
+			maxLen = len(h.Pattern)
+		}
+	}
+	if maxLen <= 0 {
+		return nil
+	}
+	cursorY := b.CursorY
+	err := b.ReadUntil((offset + n + maxLen) / lineSize)
+	b.CursorY = cursorY
+	if err != nil {
+		return err
+	}
+	for _, h := range highlights {
+		if !h.Enabled {
+			continue
+		}
+		for start := offset - len(h.Pattern) + 1; start < offset+n; start++ {
+			if matchAt(b, start, h.Pattern) {
+				for i := range h.Pattern {
+					highlighted[int64(start+i)] = h.Color
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func highlightColor(offset int64) (string, bool) {
+	color, ok := highlighted[offset]
+	return color, ok
+}
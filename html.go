@@ -0,0 +1,145 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+var ansiColors = [...]string{
+	"#000000", "#cd0000", "#00cd00", "#cdcd00",
+	"#0000ee", "#cd00cd", "#00cdcd", "#e5e5e5",
+}
+
+var ansiBrightColors = [...]string{
+	"#7f7f7f", "#ff0000", "#00ff00", "#ffff00",
+	"#5c5cff", "#ff00ff", "#00ffff", "#ffffff",
+}
+
+type sgrState struct {
+	fg, bg    int
+	bold      bool
+	reverse   bool
+	underline bool
+}
+
+func (s *sgrState) reset() {
+	*s = sgrState{fg: 7, bg: 0}
+}
+
+func (s *sgrState) apply(params string) {
+	if params == "" {
+		s.reset()
+		return
+	}
+	for _, p := range strings.Split(params, ";") {
+		n, err := strconv.Atoi(p)
+		if err != nil {
+			continue
+		}
+		switch {
+		case n == 0:
+			s.reset()
+		case n == 1:
+			s.bold = true
+		case n == 22:
+			s.bold = false
+		case n == 4:
+			s.underline = true
+		case n == 24:
+			s.underline = false
+		case n == 7:
+			s.reverse = true
+		case n == 27:
+			s.reverse = false
+		case 30 <= n && n <= 37:
+			s.fg = n - 30
+		case 40 <= n && n <= 47:
+			s.bg = n - 40
+		}
+	}
+}
+
+func (s *sgrState) style() string {
+	fg := ansiColors[s.fg]
+	if s.bold {
+		fg = ansiBrightColors[s.fg]
+	}
+	bg := ansiColors[s.bg]
+	if s.reverse {
+		fg, bg = bg, fg
+	}
+	style := fmt.Sprintf("color:%s;background-color:%s", fg, bg)
+	if s.bold {
+		style += ";font-weight:bold"
+	}
+	if s.underline {
+		style += ";text-decoration:underline"
+	}
+	return style
+}
+
+// sgrToHTML converts the text colored with SGR escape sequences into HTML.
+// The other escape sequences are removed.
+func sgrToHTML(w io.Writer, line string, state *sgrState) {
+	for len(line) > 0 {
+		if line[0] == '\x1B' && len(line) >= 2 && line[1] == '[' {
+			end := strings.IndexFunc(line[2:], func(c rune) bool {
+				return c >= '@' && c <= '~'
+			})
+			if end < 0 {
+				return
+			}
+			if line[2+end] == 'm' {
+				state.apply(line[2 : 2+end])
+			}
+			line = line[3+end:]
+			continue
+		}
+		end := strings.IndexByte(line, '\x1B')
+		if end < 0 {
+			end = len(line)
+		}
+		fmt.Fprintf(w, `<span style="%s">%s</span>`,
+			state.style(), html.EscapeString(line[:end]))
+		line = line[end:]
+	}
+}
+
+func writeHTML(fname string, lines []string) error {
+	fd, err := os.Create(fname)
+	if err != nil {
+		return err
+	}
+	io.WriteString(fd, "<!DOCTYPE html>\n<html><head><meta charset=\"utf-8\"><title>binview</title></head>\n")
+	io.WriteString(fd, "<body style=\"background-color:#000000\"><pre style=\"font-family:monospace\">\n")
+	var state sgrState
+	for _, line := range lines {
+		state.reset()
+		sgrToHTML(fd, line, &state)
+		io.WriteString(fd, "\n")
+	}
+	io.WriteString(fd, "</pre></body></html>\n")
+	return fd.Close()
+}
+
+// snapshot renders the current screen without touching the screen cache.
+func (b *Buffer) snapshot(startRow, frozen, csrpos, csrlin, w, h int) ([]string, error) {
+	b.CursorY = startRow
+	saveCache := cache
+	cache = map[int]string{}
+	lineFeed = "\n"
+	defer func() {
+		cache = saveCache
+		lineFeed = "\r\n"
+	}()
+
+	var buffer strings.Builder
+	if _, err := b.View(frozen, csrpos, csrlin, w, h, &buffer); err != nil {
+		return nil, err
+	}
+	return strings.Split(buffer.String(), "\n"), nil
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"strconv"
+	"strings"
+)
+
+// HALF_BLOCK shows the upper pixel as the foreground color and the lower
+// pixel as the background color.
+const HALF_BLOCK = "▀"
+
+// PALETTE_SIZE is the number of the colors of the palette for -palette
+const PALETTE_SIZE = 256
+
+// imagePreview shows the rows as the grayscale image instead of the hex
+// pane. One pixel is one byte and one terminal row is two rows of bytes.
+var imagePreview = false
+
+// palette maps the bytes to the RGB colors for the image preview.
+// It is nil for the grayscale.
+var palette [][3]byte
+
+// grayColor maps the byte to the grayscale ramp of the 256-color palette.
+func grayColor(c byte) int {
+	return 232 + int(c)*24/256
+}
+
+// pixelColor returns the parameter of SGR for the color of c.
+// layer is 38 for the foreground and 48 for the background.
+func pixelColor(layer int, c byte) string {
+	if palette != nil {
+		rgb := palette[c]
+		return fmt.Sprintf("%d;2;%d;%d;%d", layer, rgb[0], rgb[1], rgb[2])
+	}
+	return fmt.Sprintf("%d;5;%d", layer, grayColor(c))
+}
+
+// imageRow draws the two rows of bytes as one row of the half blocks.
+// lower may be shorter than upper or nil.
+func imageRow(upper, lower []byte, w int) string {
+	var buffer strings.Builder
+	for x := 0; x < len(upper) && x < w; x++ {
+		if x < len(lower) {
+			fmt.Fprintf(&buffer, "\x1B[%s;%sm%s", pixelColor(38, upper[x]), pixelColor(48, lower[x]), HALF_BLOCK)
+		} else {
+			fmt.Fprintf(&buffer, "\x1B[%s;40m%s", pixelColor(38, upper[x]), HALF_BLOCK)
+		}
+	}
+	buffer.WriteString(_ANSI_RESET)
+	buffer.WriteString(ERASE_LINE)
+	return buffer.String()
+}
+
+// loadPalette reads the palette of 256 colors. The file is the binary of
+// 768 bytes (R, G and B for each color like .act) or the text of one color
+// per line as RRGGBB, #RRGGBB or "R G B" in decimal.
+func loadPalette(fname string) ([][3]byte, error) {
+	bin, err := ioutil.ReadFile(fname)
+	if err != nil {
+		return nil, err
+	}
+	result := make([][3]byte, 0, PALETTE_SIZE)
+	if len(bin) == PALETTE_SIZE*3 || len(bin) == PALETTE_SIZE*3+4 {
+		for i := 0; i < PALETTE_SIZE; i++ {
+			result = append(result, [3]byte{bin[i*3], bin[i*3+1], bin[i*3+2]})
+		}
+		return result, nil
+	}
+	if bytes.IndexByte(bin, 0) >= 0 {
+		return nil, fmt.Errorf("%s: %d bytes (expected %d bytes for the binary palette)", fname, len(bin), PALETTE_SIZE*3)
+	}
+	sc := bufio.NewScanner(bytes.NewReader(bin))
+	for lnum := 1; sc.Scan(); lnum++ {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" {
+			continue
+		}
+		rgb, err := parseColor(line)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", fname, lnum, err)
+		}
+		result = append(result, rgb)
+	}
+	if len(result) != PALETTE_SIZE {
+		return nil, fmt.Errorf("%s: %d colors (expected %d)", fname, len(result), PALETTE_SIZE)
+	}
+	return result, nil
+}
+
+func parseColor(s string) ([3]byte, error) {
+	var rgb [3]byte
+	if fields := strings.Fields(s); len(fields) == 3 {
+		for i, f := range fields {
+			n, err := strconv.ParseUint(f, 10, 8)
+			if err != nil {
+				return rgb, fmt.Errorf("%q: not a color", s)
+			}
+			rgb[i] = byte(n)
+		}
+		return rgb, nil
+	}
+	hex := strings.TrimPrefix(s, "#")
+	n, err := strconv.ParseUint(hex, 16, 32)
+	if len(hex) != 6 || err != nil {
+		return rgb, fmt.Errorf("%q: not a color", s)
+	}
+	return [3]byte{byte(n >> 16), byte(n >> 8), byte(n)}, nil
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"io"
+)
+
+const INTERLEAVE_SEPARATOR = " | "
+
+// drawInterleaved draws the row for -interleave: each group of bytes is
+// followed by its text like "48 65 6C | Hel  6C 6F 21 | lo!".
+func drawInterleaved(out io.Writer, address int64, cursorPos int, slice, prev []byte) {
+	for start := 0; start < len(slice); start += *flagGroup {
+		end := start + *flagGroup
+		if end > len(slice) {
+			end = len(slice)
+		}
+		if start > 0 {
+			io.WriteString(out, "  ")
+		}
+		for i := start; i < end; i++ {
+			at := address + int64(swapIndex(i, len(slice)))
+			on, off := interleavedColor(at, i, cursorPos, slice[i:i+1], prev)
+			if i > start {
+				io.WriteString(out, " ")
+			}
+			if isUnreadable(at) {
+				fmt.Fprintf(out, "%s??%s", on, off)
+			} else {
+				fmt.Fprintf(out, "%s%02X%s", on, slice[i], off)
+			}
+		}
+		io.WriteString(out, INTERLEAVE_SEPARATOR)
+		for i := start; i < end; {
+			c, length := decodeChar(slice[i:end])
+			at := address + int64(swapIndex(i, len(slice)))
+			on, off := interleavedColor(at, i, cursorPos, slice[i:i+length], prev)
+			if i <= cursorPos && cursorPos < i+length {
+				on, off = CURSOR_COLOR_ON, CURSOR_COLOR_OFF
+			}
+			if isUnreadable(at) {
+				c = '?'
+			}
+			var padding string
+			if length == 3 {
+				padding = " "
+			} else if length == 4 {
+				padding = "  "
+			}
+			fmt.Fprintf(out, "%s%c%s%s", on, c, off, padding)
+			i += length
+		}
+	}
+	io.WriteString(out, ERASE_LINE)
+}
+
+func interleavedColor(at int64, i, cursorPos int, data, prev []byte) (string, string) {
+	switch {
+	case i == cursorPos:
+		return CURSOR_COLOR_ON, CURSOR_COLOR_OFF
+	case isSelected(at):
+		return SELECT_COLOR_ON, SELECT_COLOR_OFF
+	case isFound(at):
+		return FOUND_COLOR_ON, FOUND_COLOR_OFF
+	case isUnreadable(at):
+		return UNREADABLE_COLOR_ON, UNREADABLE_COLOR_OFF
+	}
+	if color, ok := highlightColor(at); ok {
+		return color, HIGHLIGHT_COLOR_OFF
+	}
+	if isDifferent(at, data) || isChangedFromAbove(prev, i, data) {
+		return DIFF_COLOR_ON, DIFF_COLOR_OFF
+	}
+	if on, off, ok := classColor(data[0]); byteClassColor && ok {
+		return on, off
+	}
+	return CELL1_COLOR_ON, CELL1_COLOR_OFF
+}
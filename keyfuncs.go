@@ -4,6 +4,7 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/mattn/go-tty"
 )
@@ -13,7 +14,7 @@ func unshiftLines(b *Buffer, rowIndex int, carry byte) {
 		carry = b.Unshift(i, carry)
 	}
 	last := b.Slices[b.Count()-1]
-	if len(last) < LINE_SIZE {
+	if len(last) < lineSize {
 		last = append(last, carry)
 		b.Slices[b.Count()-1] = last
 	} else {
@@ -47,7 +48,7 @@ func deleteOne(b *Buffer, rowIndex, colIndex int) {
 		carry = b.Shift(i, carry)
 	}
 	csrline := b.Slices[rowIndex]
-	if colIndex < LINE_SIZE {
+	if colIndex < lineSize {
 		copy(csrline[colIndex:], csrline[colIndex+1:])
 	}
 	setLastByte(csrline, carry)
@@ -69,21 +70,51 @@ func deleteOne(b *Buffer, rowIndex, colIndex int) {
 
 var overWritten = map[string]struct{}{}
 
-func write(buffer *Buffer, tty1 *tty.TTY, out io.Writer, args []string) error {
+// askOutputName asks the file name to write with the input file as the default.
+// For the gzip-compressed input, the name without .gz is suggested
+// not to overwrite the compressed file with the decompressed data,
+// and for the input sliced with -offset or -length, FILE.new is
+// suggested not to replace the whole file with the slice.
+func askOutputName(out io.Writer, args []string, compressed, sliced bool) (string, error) {
 	fname := "output.new"
 	var err error
-	if len(args) >= 1 {
+	if *flagOutput != "" {
+		fname = *flagOutput
+	} else if len(args) >= 1 {
 		fname, err = filepath.Abs(args[0])
 		if err != nil {
-			return err
+			return "", err
+		}
+		if sliced {
+			fname += ".new"
+		} else if compressed {
+			if strings.HasSuffix(strings.ToLower(fname), ".gz") {
+				fname = fname[:len(fname)-3]
+			} else {
+				fname += ".new"
+			}
 		}
 	}
-	fname, err = getline(out, "write to>", fname)
+	return getline(out, "write to>", fname)
+}
+
+// isInputFile reports whether fname is the same file as one of args.
+func isInputFile(fname string, args []string) bool {
+	stat1, err := os.Stat(fname)
 	if err != nil {
-		return err
+		return false
 	}
+	for _, arg := range args {
+		if stat2, err := os.Stat(arg); err == nil && os.SameFile(stat1, stat2) {
+			return true
+		}
+	}
+	return false
+}
+
+func write(buffer *Buffer, tty1 *tty.TTY, out io.Writer, fname string) error {
 	buffer.ReadAll()
-	fd, err := os.OpenFile(fname, os.O_EXCL|os.O_CREATE, 0666)
+	fd, err := os.OpenFile(fname, os.O_WRONLY|os.O_EXCL|os.O_CREATE, 0666)
 	if os.IsExist(err) {
 		if _, ok := overWritten[fname]; ok {
 			os.Remove(fname)
@@ -96,7 +127,7 @@ func write(buffer *Buffer, tty1 *tty.TTY, out io.Writer, args []string) error {
 			os.Rename(fname, backupName)
 			overWritten[fname] = struct{}{}
 		}
-		fd, err = os.OpenFile(fname, os.O_EXCL|os.O_CREATE, 0666)
+		fd, err = os.OpenFile(fname, os.O_WRONLY|os.O_EXCL|os.O_CREATE, 0666)
 	}
 	if err != nil {
 		return err
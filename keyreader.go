@@ -0,0 +1,45 @@
+package main
+
+import (
+	"time"
+
+	"github.com/mattn/go-tty"
+)
+
+const BLINK_INTERVAL = 500 * time.Millisecond
+
+type keyResult struct {
+	key string
+	err error
+}
+
+// KeyReader reads keys in the background to wait them with timeout.
+type KeyReader struct {
+	keys    chan keyResult
+	pending bool
+}
+
+func NewKeyReader() *KeyReader {
+	return &KeyReader{keys: make(chan keyResult)}
+}
+
+// Wait waits a key like getkey for timeout at most. ok is false on timeout
+// and then the key typed later is returned by the next call.
+func (k *KeyReader) Wait(tty1 *tty.TTY, timeout time.Duration) (key string, ok bool, err error) {
+	if !k.pending {
+		k.pending = true
+		go func() {
+			key, err := getkey(tty1)
+			k.keys <- keyResult{key: key, err: err}
+		}()
+	}
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case result := <-k.keys:
+		k.pending = false
+		return result.key, true, result.err
+	case <-timer.C:
+		return "", false, nil
+	}
+}
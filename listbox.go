@@ -0,0 +1,82 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/mattn/go-runewidth"
+	"github.com/mattn/go-tty"
+)
+
+// listBox shows items over the screen (height lines and the status line)
+// and returns the index selected by Enter, or -1 when canceled.
+// The cursor should be on the status line before and after calling it.
+func listBox(tty1 *tty.TTY, out io.Writer, title string, items []string, width, height int) (int, error) {
+	if len(items) <= 0 {
+		return -1, nil
+	}
+	if height < 1 {
+		height = 1
+	}
+	current := 0
+	top := 0
+	for {
+		if current < top {
+			top = current
+		} else if current >= top+height {
+			top = current - height + 1
+		}
+		if height > 1 {
+			fmt.Fprintf(out, "\r\x1B[%dA", height)
+		} else {
+			io.WriteString(out, "\r\x1B[A")
+		}
+		for i := top; i < top+height; i++ {
+			if i < len(items) {
+				line := runewidth.Truncate(items[i], width, "")
+				if i == current {
+					fmt.Fprintf(out, "%s%s%s", CURSOR_COLOR_ON, line, _ANSI_RESET)
+				} else {
+					fmt.Fprintf(out, "%s%s%s", CELL1_COLOR_ON, line, _ANSI_RESET)
+				}
+			}
+			io.WriteString(out, ERASE_LINE)
+			io.WriteString(out, "\r\n")
+		}
+		status := fmt.Sprintf("%s (%d/%d) j,k:move Enter:jump q:cancel", title, current+1, len(items))
+		fmt.Fprintf(out, "%s%s%s%s", _ANSI_YELLOW, runewidth.Truncate(status, width, ""), _ANSI_RESET, ERASE_LINE)
+
+		ch, err := getkey(tty1)
+		if err != nil {
+			return -1, err
+		}
+		switch ch {
+		case "j", _KEY_DOWN, _KEY_CTRL_N:
+			if current < len(items)-1 {
+				current++
+			}
+		case "k", _KEY_UP, _KEY_CTRL_P:
+			if current > 0 {
+				current--
+			}
+		case " ", _KEY_CTRL_F:
+			current += height
+			if current >= len(items) {
+				current = len(items) - 1
+			}
+		case "\b", _KEY_CTRL_B:
+			current -= height
+			if current < 0 {
+				current = 0
+			}
+		case "<":
+			current = 0
+		case ">", "G":
+			current = len(items) - 1
+		case "\r", "\n":
+			return current, nil
+		case "q", _KEY_ESC:
+			return -1, nil
+		}
+	}
+}
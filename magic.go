@@ -0,0 +1,54 @@
+package main
+
+var magicTable = []struct {
+	offset int
+	magic  string
+	name   string
+}{
+	{0, "\x89PNG\r\n\x1A\n", "PNG image"},
+	{0, "\xFF\xD8\xFF", "JPEG image"},
+	{0, "GIF87a", "GIF image"},
+	{0, "GIF89a", "GIF image"},
+	{0, "II*\x00", "TIFF image"},
+	{0, "MM\x00*", "TIFF image"},
+	{0, "BM", "BMP image"},
+	{0, "%PDF-", "PDF document"},
+	{0, "\x7FELF", "ELF executable"},
+	{0, "MZ", "DOS/Windows executable"},
+	{0, "\xCF\xFA\xED\xFE", "Mach-O executable"},
+	{0, "\xCE\xFA\xED\xFE", "Mach-O executable"},
+	{0, "\xCA\xFE\xBA\xBE", "Java class or Mach-O universal binary"},
+	{0, "\x00asm", "WebAssembly binary"},
+	{0, "PK\x03\x04", "ZIP archive"},
+	{0, "PK\x05\x06", "ZIP archive (empty)"},
+	{0, "7z\xBC\xAF\x27\x1C", "7-Zip archive"},
+	{0, "Rar!\x1A\x07", "RAR archive"},
+	{0, "!<arch>\n", "ar archive"},
+	{257, "ustar", "tar archive"},
+	{0, "\x1F\x8B", "gzip compressed data"},
+	{0, "BZh", "bzip2 compressed data"},
+	{0, "\xFD7zXZ\x00", "xz compressed data"},
+	{0, "\x28\xB5\x2F\xFD", "zstd compressed data"},
+	{0, "\xD0\xCF\x11\xE0\xA1\xB1\x1A\xE1", "OLE2 compound document (MS Office)"},
+	{0, "SQLite format 3\x00", "SQLite database"},
+	{0, "RIFF", "RIFF data (WAV/AVI/WebP)"},
+	{0, "OggS", "Ogg data"},
+	{0, "fLaC", "FLAC audio"},
+	{0, "ID3", "MP3 audio"},
+	{4, "ftyp", "ISO media (MP4/MOV)"},
+	{0, "\xEF\xBB\xBF", "UTF-8 text with BOM"},
+}
+
+// guessFileType returns the name of the file type guessed from
+// the magic number at the top of the data, or "" when unknown.
+func guessFileType(b *Buffer) (string, error) {
+	for _, m := range magicTable {
+		if err := b.ReadUntil((m.offset + len(m.magic) - 1) / lineSize); err != nil {
+			return "", err
+		}
+		if matchAt(b, m.offset, []byte(m.magic)) {
+			return m.name, nil
+		}
+	}
+	return "", nil
+}
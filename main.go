@@ -1,14 +1,18 @@
 package main
 
 import (
+	"errors"
+	"flag"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
 	"strconv"
 	"strings"
 	"unicode/utf8"
 
 	"github.com/mattn/go-colorable"
+	"github.com/mattn/go-isatty"
 	"github.com/mattn/go-runewidth"
 	"github.com/mattn/go-tty"
 )
@@ -20,62 +24,176 @@ const (
 	CELL1_COLOR_OFF  = ""
 	CELL2_COLOR_ON   = "\x1B[37;40;1m"
 	CELL2_COLOR_OFF  = "\x1B[22m"
+	SELECT_COLOR_ON  = "\x1B[37;44;1m"
+	SELECT_COLOR_OFF = "\x1B[40;22m"
+	FOUND_COLOR_ON   = "\x1B[30;42;22m"
+	FOUND_COLOR_OFF  = "\x1B[37;40m"
+	DIFF_COLOR_ON    = "\x1B[31;40;1m"
+	DIFF_COLOR_OFF   = "\x1B[37;22m"
 	ERASE_LINE       = "\x1B[0K"
 	ERASE_SCRN_AFTER = "\x1B[0J"
 )
 
-const LINE_SIZE = 16
+// lineSize is the number of bytes per row
+var lineSize = 16
+
+// homeAddress is the address of the top of the data shown on the screen
+var homeAddress int64 = 0
+
+const (
+	MIN_LINE_SIZE = 1
+	MAX_LINE_SIZE = 128
+)
 
 // See. en.wikipedia.org/wiki/Unicode_control_characters#Control_pictures
 
-func draw(out io.Writer, address int, cursorPos int, slice []byte) {
+// draw outputs one row. When base >= 0, the address is shown relative to base.
+// prev is the row above (nil for the first row).
+func draw(out io.Writer, address int64, base int64, cursorPos int, slice, prev []byte) {
 	if cursorPos >= 0 {
 		io.WriteString(out, _ANSI_UNDERLINE_ON)
 		defer io.WriteString(out, _ANSI_UNDERLINE_OFF)
 	}
-	fmt.Fprintf(out, "%s%08X%s ", CELL2_COLOR_ON, address, CELL2_COLOR_OFF)
+	if cursorBlinkOff {
+		// keep the underline of the row
+		cursorPos = -1
+	}
+	if swapView {
+		slice, prev = swapPairs(slice), swapPairs(prev)
+		cursorPos = swapIndex(cursorPos, len(slice))
+	}
+	if showRowNumber {
+		fmt.Fprintf(out, "%s%7d%s ", CELL1_COLOR_ON, address/int64(lineSize)+1, CELL1_COLOR_OFF)
+	}
+	if base >= 0 {
+		fmt.Fprintf(out, "%s+%07X%s", CELL2_COLOR_ON, address-base, CELL2_COLOR_OFF)
+	} else {
+		fmt.Fprintf(out, "%s%08X%s", CELL2_COLOR_ON, homeAddress+address, CELL2_COLOR_OFF)
+	}
+	if decimalAddress {
+		var decimal string
+		if base >= 0 {
+			decimal = fmt.Sprintf("(+%d)", address-base)
+		} else {
+			decimal = fmt.Sprintf("(%d)", homeAddress+address)
+		}
+		fmt.Fprintf(out, " %s%-12s%s", CELL2_COLOR_ON, decimal, CELL2_COLOR_OFF)
+	}
+	if endAddress {
+		fmt.Fprintf(out, " %s-%08X%s", CELL2_COLOR_ON, dataLength-address, CELL2_COLOR_OFF)
+	}
+	io.WriteString(out, *flagAddressSep)
+	if hideHexPane {
+		io.WriteString(out, ERASE_LINE)
+		return
+	}
+	if *flagInterleave && !hideTextPane && *flagMode == "hex" {
+		drawInterleaved(out, address, cursorPos, slice, prev)
+		return
+	}
+	switch *flagMode {
+	case "char":
+		drawChars(out, address, cursorPos, slice, prev, charCell)
+		return
+	case "int8":
+		drawChars(out, address, cursorPos, slice, prev, int8Cell)
+		return
+	}
 	for i, s := range slice {
+		at := address + int64(swapIndex(i, len(slice)))
 		var fieldSeperator string
 		if i > 0 {
-			fieldSeperator = " "
+			if i%*flagGroup == 0 {
+				fieldSeperator = *flagGroupSep
+			} else {
+				fieldSeperator = " "
+			}
 		}
 		var on, off string
 		if i == cursorPos {
 			on = CURSOR_COLOR_ON
 			off = CURSOR_COLOR_OFF
-		} else if ((i >> 2) & 1) == 0 {
+		} else if isSelected(at) {
+			on = SELECT_COLOR_ON
+			off = SELECT_COLOR_OFF
+		} else if isFound(at) {
+			on = FOUND_COLOR_ON
+			off = FOUND_COLOR_OFF
+		} else if color, ok := highlightColor(at); ok {
+			on = color
+			off = HIGHLIGHT_COLOR_OFF
+		} else if cOn, cOff, ok := classColor(s); byteClassColor && ok {
+			on = cOn
+			off = cOff
+		} else if (i/(*flagGroup))%2 == 0 {
 			on = CELL1_COLOR_ON
 			off = CELL1_COLOR_OFF
 		} else {
 			on = CELL2_COLOR_ON
 			off = CELL2_COLOR_OFF
 		}
+		if isUnreadable(at) {
+			if i != cursorPos {
+				on = UNREADABLE_COLOR_ON
+				off = UNREADABLE_COLOR_OFF
+			}
+			fmt.Fprintf(out, "%s%s??%s", fieldSeperator, on, off)
+			continue
+		}
+		if _, ok := highlightColor(at); !ok && i != cursorPos &&
+			!isSelected(at) && !isFound(at) {
+			if mask := diffMask(at, prev, i, s); mask != 0 {
+				fmt.Fprintf(out, "%s%s", fieldSeperator, diffNibbles(s, mask, on, off))
+				continue
+			}
+		}
 		fmt.Fprintf(out, "%s%s%02X%s", fieldSeperator, on, s, off)
 	}
-	io.WriteString(out, " ")
-	for i := len(slice); i < LINE_SIZE; i++ {
+	for i := len(slice); i < lineSize; i++ {
 		io.WriteString(out, "   ")
 	}
+	io.WriteString(out, *flagPaneSep)
+	if hideTextPane {
+		io.WriteString(out, ERASE_LINE)
+		return
+	}
+	if wordPaneSize > 0 {
+		drawWords(out, cursorPos, slice)
+	}
 
 	for i := 0; i < len(slice); {
-		c := rune(slice[i])
-		length := 1
-		if c < ' ' || c == '\u007F' {
-			c = '.'
-		} else if c >= utf8.RuneSelf {
-			c, length = utf8.DecodeRune(slice[i:])
-			if c == utf8.RuneError {
-				c = '.'
-			}
-		}
+		c, length := decodeChar(slice[i:])
+		at := address + int64(swapIndex(i, len(slice)))
 		var on, off, padding string
 		if i <= cursorPos && cursorPos < i+length {
 			on = CURSOR_COLOR_ON
 			off = CURSOR_COLOR_OFF
+		} else if isSelected(at) {
+			on = SELECT_COLOR_ON
+			off = SELECT_COLOR_OFF
+		} else if isFound(at) {
+			on = FOUND_COLOR_ON
+			off = FOUND_COLOR_OFF
+		} else if color, ok := highlightColor(at); ok {
+			on = color
+			off = HIGHLIGHT_COLOR_OFF
+		} else if isDifferent(at, slice[i:i+length]) || isChangedFromAbove(prev, i, slice[i:i+length]) {
+			on = DIFF_COLOR_ON
+			off = DIFF_COLOR_OFF
+		} else if cOn, cOff, ok := classColor(slice[i]); byteClassColor && ok {
+			on = cOn
+			off = cOff
 		} else {
 			on = CELL1_COLOR_ON
 			off = CELL1_COLOR_OFF
 		}
+		if isUnreadable(at) {
+			c = '?'
+			if !(i <= cursorPos && cursorPos < i+length) {
+				on = UNREADABLE_COLOR_ON
+				off = UNREADABLE_COLOR_OFF
+			}
+		}
 		if length == 3 {
 			padding = " "
 		} else if length == 4 {
@@ -89,50 +207,151 @@ func draw(out io.Writer, address int, cursorPos int, slice []byte) {
 
 var cache = map[int]string{}
 
+// lineFeed is written between rows by View. "\r" is for Linux and go-tty.
+// The output not for the terminal uses the plain "\n".
+var lineFeed = "\r\n"
+
+// cursorBlinkOff hides the cursor cell while blinking with -blink
+var cursorBlinkOff = false
+
+// showRowNumber prepends the row number counted from 1 to each row
+var showRowNumber = false
+
+// relativeAddress makes the address column relative to the top of the screen
+var relativeAddress = false
+
+// decimalAddress appends the address in decimal to the address column
+var decimalAddress = false
+
+// endAddress appends the offset from the end of the data to the address column
+var endAddress = false
+
+// dataLength is the length of the whole data for endAddress
+var dataLength int64
+
 const CELL_WIDTH = 12
 
-func (b *Buffer) View(csrpos, csrlin, w, h int, out io.Writer) (int, error) {
+// View draws h lines from b.CursorY.
+// When frozen row is above b.CursorY, it is drawn at the top as a header.
+// csrlin is the row of the cursor relative to b.CursorY.
+func (b *Buffer) View(frozen, csrpos, csrlin, w, h int, out io.Writer) (int, error) {
 	count := 0
 	lfCount := 0
+	putLine := func(line string) {
+		if count > 0 {
+			lfCount++
+			io.WriteString(out, lineFeed)
+		}
+		if f := cache[count]; *flagNoCache || f != line {
+			io.WriteString(out, line)
+			cache[count] = line
+		}
+		count++
+	}
+	if endAddress {
+		b.ReadAll()
+		dataLength = int64(b.Len())
+	}
+	if !fitPanes(w) {
+		putLine(runewidth.Truncate(TOO_SMALL_MESSAGE, w, "") + ERASE_LINE)
+		return lfCount, nil
+	}
+	if imagePreview {
+		for count < h {
+			upper, _, err := b.Fetch()
+			if err == io.EOF {
+				return lfCount, nil
+			}
+			if err != nil {
+				return lfCount, err
+			}
+			lower, _, err := b.Fetch()
+			if err != nil && err != io.EOF {
+				return lfCount, err
+			}
+			putLine(imageRow(upper, lower, w))
+		}
+		return lfCount, nil
+	}
+	top := b.CursorY
+	base := int64(-1)
+	if relativeAddress {
+		base = int64(top) * int64(lineSize)
+	}
+	drawLine := func(row int, address int64, cursorPos int, record []byte) error {
+		var prev []byte
+		if row > 0 {
+			prev = b.Line(row - 1)
+		}
+		if err := updateHighlights(b, int(address), len(record)); err != nil {
+			return err
+		}
+		var buffer strings.Builder
+		draw(&buffer, address, base, cursorPos, record, prev)
+		putLine(buffer.String())
+		return nil
+	}
+	if 0 <= frozen && frozen < top && h > 1 {
+		if err := updateHighlights(b, frozen*lineSize, len(b.Line(frozen))); err != nil {
+			return lfCount, err
+		}
+		var buffer strings.Builder
+		draw(&buffer, int64(frozen)*int64(lineSize), -1, -1, b.Line(frozen), nil)
+		putLine(buffer.String())
+	}
+	squeezed := false
 	for {
 		if count >= h {
 			return lfCount, nil
 		}
+		row := b.CursorY
 		record, address, err := b.Fetch()
 		if err == io.EOF {
+			if count == 0 && b.Count() <= 0 {
+				// for the empty data
+				return lfCount, drawLine(0, 0, -1, []byte{})
+			}
 			return lfCount, nil
 		}
 		if err != nil {
 			return lfCount, err
 		}
-		if count > 0 {
-			lfCount++
-			io.WriteString(out, "\r\n") // "\r" is for Linux and go-tty
+		if *flagSqueeze && row > top && row-top != csrlin && isSameRow(b, row) {
+			if !squeezed {
+				putLine(SQUEEZE_MARK + ERASE_LINE)
+				squeezed = true
+			}
+			continue
+		}
+		squeezed = false
+		if row > top && separatorBefore(row) {
+			putLine(CELL2_COLOR_ON + strings.Repeat(SEPARATOR_MARK, w) + CELL2_COLOR_OFF + ERASE_LINE)
+			if count >= h {
+				b.CursorY = row
+				return lfCount, nil
+			}
 		}
 		var cursorPos int
-		if count == csrlin {
+		if row-top == csrlin {
 			cursorPos = csrpos
 		} else {
 			cursorPos = -1
 		}
-		var buffer strings.Builder
-		draw(&buffer, address, cursorPos, record)
-		line := buffer.String()
-		if f := cache[count]; f != line {
-			io.WriteString(out, line)
-			cache[count] = line
+		if err := drawLine(row, address, cursorPos, record); err != nil {
+			return lfCount, err
 		}
-		count++
 	}
 }
 
 const (
-	_ANSI_CURSOR_OFF    = "\x1B[?25l"
-	_ANSI_CURSOR_ON     = "\x1B[?25h"
-	_ANSI_YELLOW        = "\x1B[0;33;1m"
-	_ANSI_RESET         = "\x1B[0m"
-	_ANSI_UNDERLINE_ON  = "\x1B[4m"
-	_ANSI_UNDERLINE_OFF = "\x1B[24m"
+	_ANSI_CURSOR_OFF     = "\x1B[?25l"
+	_ANSI_CURSOR_ON      = "\x1B[?25h"
+	_ANSI_YELLOW         = "\x1B[0;33;1m"
+	_ANSI_RESET          = "\x1B[0m"
+	_ANSI_UNDERLINE_ON   = "\x1B[4m"
+	_ANSI_UNDERLINE_OFF  = "\x1B[24m"
+	_ANSI_ALT_SCREEN_ON  = "\x1B[?1049h\x1B[H"
+	_ANSI_ALT_SCREEN_OFF = "\x1B[?1049l"
 )
 
 const (
@@ -143,6 +362,8 @@ const (
 	_KEY_CTRL_L = "\x0C"
 	_KEY_CTRL_N = "\x0E"
 	_KEY_CTRL_P = "\x10"
+	_KEY_CTRL_V = "\x16"
+	_KEY_CTRL_Y = "\x19"
 	_KEY_DOWN   = "\x1B[B"
 	_KEY_ESC    = "\x1B"
 	_KEY_LEFT   = "\x1B[D"
@@ -183,112 +404,750 @@ func (c *Clip) Len() int {
 	return len(c.data)
 }
 
+// pipeOut is the standard output to write the selection with Q when
+// the screen is drawn on the standard error. It is nil otherwise.
+var pipeOut io.Writer
+
+func isTerminal(f *os.File) bool {
+	return isatty.IsTerminal(f.Fd()) || isatty.IsCygwinTerminal(f.Fd())
+}
+
+// viewRows returns the number of the rows View draws on the screen.
+// The last rows give place to the pinned peek and to the lines of
+// the message wrapped with -wrap-message, which are returned too.
+func viewRows(screenWidth, screenHeight int, message string) (int, bool, []string) {
+	rows := screenHeight - 1
+	pinned := pinnedOffset >= 0 && rows > 1
+	if pinned {
+		rows--
+	}
+	var messageLines []string
+	if message != "" && *flagWrapMessage {
+		messageLines = wrapText(message, screenWidth-1)
+		if extra := len(messageLines) - 1; extra > 0 {
+			if extra > rows-1 {
+				extra = rows - 1
+				messageLines = messageLines[:extra+1]
+			}
+			rows -= extra
+		}
+	}
+	return rows, pinned, messageLines
+}
+
 func mains(args []string) error {
-	disable := colorable.EnableColorsStdout(nil)
-	if disable != nil {
-		defer disable()
+	var out io.Writer
+	if script := os.Getenv("BINVIEW_KEYS"); script != "" {
+		// print the frames without the terminal for the tests
+		keys, err := parseKeys(script)
+		if err != nil {
+			return fmt.Errorf("BINVIEW_KEYS: %w", err)
+		}
+		scriptedKeys = keys
+		out = os.Stdout
+	} else if isTerminal(os.Stdout) {
+		disable := colorable.EnableColorsStdout(nil)
+		if disable != nil {
+			defer disable()
+		}
+		out = colorable.NewColorableStdout()
+	} else if isTerminal(os.Stderr) {
+		// draw on the standard error and keep the standard output for Q
+		out = colorable.NewColorableStderr()
+		pipeOut = os.Stdout
+	} else {
+		return errors.New("binview requires a terminal: neither the standard output nor the standard error is a terminal")
+	}
+	dumb := *flagDumb || os.Getenv("TERM") == "dumb" || scriptedKeys != nil
+	if dumb {
+		out = dumbWriter{out}
 	}
-	out := colorable.NewColorableStdout()
+
+	// On panic, the deferred functions below (closing tty) run first
+	// and then this restores colors and the cursor before re-panicking.
+	defer func() {
+		if e := recover(); e != nil {
+			io.WriteString(out, _ANSI_RESET+_ANSI_UNDERLINE_OFF+_ANSI_CURSOR_ON+"\n")
+			panic(e)
+		}
+	}()
 
 	io.WriteString(out, _ANSI_CURSOR_OFF)
 	defer io.WriteString(out, _ANSI_CURSOR_ON)
 
-	pin, err := NewArgf(args)
-	if err != nil {
-		return err
+	altScreen := *flagAltScreen && !dumb
+	if altScreen {
+		io.WriteString(out, _ANSI_ALT_SCREEN_ON)
+		defer io.WriteString(out, _ANSI_ALT_SCREEN_OFF)
+	}
+
+	if *flagOnExit != "keep" && *flagOnExit != "plain" && *flagOnExit != "erase" {
+		return fmt.Errorf("-on-exit: %s: must be keep, plain or erase", *flagOnExit)
+	}
+
+	if *flagReadSize < MAX_LINE_SIZE {
+		return fmt.Errorf("-read-size: %d: must be %d or more", *flagReadSize, MAX_LINE_SIZE)
+	}
+	readSize = *flagReadSize
+
+	if *flagExitRows < 0 {
+		return fmt.Errorf("-exit-rows: %d: must not be negative", *flagExitRows)
+	}
+
+	autoWidth := *flagWidth == "auto"
+	if !autoWidth {
+		width, err := strconv.Atoi(*flagWidth)
+		if err != nil || width < MIN_LINE_SIZE || width > MAX_LINE_SIZE {
+			return fmt.Errorf("-width: %s: must be auto or from %d to %d", *flagWidth, MIN_LINE_SIZE, MAX_LINE_SIZE)
+		}
+		lineSize = width
+	}
+
+	switch *flagWordPane {
+	case 0, 2, 4, 8:
+		wordPaneSize = *flagWordPane
+	default:
+		return fmt.Errorf("-word-pane: %d: must be 0, 2, 4 or 8", *flagWordPane)
+	}
+
+	if *flagGroup < 1 {
+		return fmt.Errorf("-group: %d: must be 1 or more", *flagGroup)
+	}
+	if runewidth.StringWidth(*flagGroupSep) != 1 {
+		return fmt.Errorf("-group-sep: %q: must be one character", *flagGroupSep)
+	}
+
+	if err := setCharset(*flagCharset); err != nil {
+		return fmt.Errorf("-charset: %w", err)
+	}
+	previousCharset = ""
+
+	if *flagHeader < 0 || *flagRecord < 0 {
+		return errors.New("-header and -record: must not be negative")
+	}
+
+	if *flagMode != "hex" && *flagMode != "char" && *flagMode != "int8" {
+		return fmt.Errorf("-mode: %s: must be hex, char or int8", *flagMode)
+	}
+
+	var pin io.ReadCloser
+	var err error
+	if *flagBase != "" {
+		baseline, err = ioutil.ReadFile(*flagBase)
+		if err != nil {
+			return err
+		}
+	}
+	if *flagPalette != "" {
+		palette, err = loadPalette(*flagPalette)
+		if err != nil {
+			return err
+		}
+	}
+	var fields []Field
+	if *flagFields != "" {
+		fields, err = parseFields(*flagFields)
+		if err != nil {
+			return fmt.Errorf("-fields: %w", err)
+		}
+	}
+	if *flagPid != 0 {
+		if len(args) > 0 {
+			return errors.New("-pid: files can not be given together")
+		}
+		address, err := strconv.ParseUint(*flagAddress, 0, 64)
+		if err != nil {
+			return fmt.Errorf("-addr: %w", err)
+		}
+		pin, err = openProcessMemory(*flagPid, int64(address), *flagLength)
+		if err != nil {
+			return err
+		}
+		homeAddress = int64(address)
+	} else {
+		pin, err = openInput(args)
+		if err != nil {
+			return err
+		}
+		homeAddress = *flagOffset
 	}
-	defer pin.Close()
+	defer func() { pin.Close() }()
 
+	progress := func(loaded int64) {
+		size := int64(-1)
+		if argf, ok := argfOf(pin); ok && argf.Size > 0 {
+			// only the slice of -offset and -length is loaded
+			size = argf.Size - *flagOffset
+			if *flagLength > 0 && size > *flagLength {
+				size = *flagLength
+			}
+		}
+		if size > 0 {
+			fmt.Fprintf(out, "\r%sloading... %d/%d bytes (%d%%)%s%s", _ANSI_YELLOW,
+				loaded, size, loaded*100/size, _ANSI_RESET, ERASE_LINE)
+		} else {
+			fmt.Fprintf(out, "\r%sloading... %d bytes%s%s", _ANSI_YELLOW,
+				loaded, _ANSI_RESET, ERASE_LINE)
+		}
+	}
 	buffer := NewBuffer(pin)
+	buffer.Progress = progress
 
-	tty1, err := tty.Open()
-	if err != nil {
-		return err
+	// sources tells the file which the cursor is in when the files are concatenated
+	var sources *Argf
+	if argf, ok := argfOf(pin); ok && len(args) > 1 {
+		sources = argf
+	}
+	fileRelative := false
+
+	// sliced tells only the part of the files is read with -offset or -length
+	sliced := *flagPid == 0 && (*flagOffset != 0 || *flagLength > 0)
+
+	// localFile enables the sidecar and the journal next to the file.
+	// They are not for the slice, whose offsets differ from the file's.
+	localFile := len(args) == 1 && !isURL(args[0]) && !sliced
+
+	var watcher *Watcher
+	if *flagWatch {
+		if len(args) != 1 {
+			return errors.New("-watch: just one file is required")
+		}
+		watcher, err = NewWatcher(args[0])
+		if err != nil {
+			return err
+		}
+	}
+
+	var tty1 *tty.TTY
+	if scriptedKeys == nil {
+		tty1, err = tty.Open()
+		if err != nil {
+			return fmt.Errorf("binview requires a terminal: %w", err)
+		}
+		defer tty1.Close()
 	}
-	defer tty1.Close()
 
 	colIndex := 0
 	rowIndex := 0
 	startRow := 0
+	frozenRow := -1
+	visualAnchor := -1
+	stride := 0
+	// stickyEnd keeps the cursor at the end of the rows after $ with -sticky-end
+	stickyEnd := false
+	// editMode overwrites the nibble at the cursor with the typed hex digit.
+	// editNibble is 0 for the upper nibble and 1 for the lower one.
+	editMode := *flagEdit
+	editNibble := 0
+
+	marks := Marks{}
+	offset := -1
+	if localFile {
+		if sideCar, err := LoadSideCar(args[0]); err == nil {
+			offset = sideCar.Offset
+			if sideCar.Marks != nil {
+				marks = sideCar.Marks
+			}
+		}
+	}
+	var cursorLog *CursorLog
+	if *flagCursorLog != "" {
+		cursorLog, err = OpenCursorLog(*flagCursorLog)
+		if err != nil {
+			return err
+		}
+		defer cursorLog.Close()
+	}
+	var session *Session
+	if *flagSession != "" {
+		session, err = LoadSession(*flagSession)
+		if os.IsNotExist(err) {
+			session = &Session{}
+		} else if err != nil {
+			return err
+		} else {
+			if err := session.Restore(buffer); err != nil {
+				return fmt.Errorf("%s: %w", *flagSession, err)
+			}
+			offset = session.Offset
+			if session.Marks != nil {
+				marks = session.Marks
+			}
+			stride = session.Stride
+		}
+	}
+	if *flagGoto != "" {
+		current := offset
+		if current < 0 {
+			current = 0
+		}
+		offset, err = parseAddress(buffer, *flagGoto, current)
+		if err != nil {
+			return fmt.Errorf("-goto: %w", err)
+		}
+	}
+	if offset >= 0 {
+		rowIndex, colIndex, err = seekOffset(buffer, offset)
+		if err != nil {
+			return err
+		}
+		startRow = rowIndex
+		if session != nil && *flagGoto == "" && session.StartRow <= rowIndex {
+			startRow = session.StartRow
+			if session.FrozenRow < buffer.Count() {
+				frozenRow = session.FrozenRow
+			}
+		}
+	}
 
 	var lastWidth, lastHeight int
 
 	clipBoard := NewClip()
+	undo := NewUndo()
 
 	isChanged := UNCHANGED
-	message := ""
+	if localFile {
+		if log, err := ReadJournal(args[0]); err == nil && len(log) > 0 {
+			if yesNo(tty1, out, "Recover unsaved edits from "+swapName(args[0])+" ? [y/n]") {
+				for _, edits := range log {
+					if err := undo.Redo(buffer, edits); err != nil {
+						return fmt.Errorf("%s: %w", swapName(args[0]), err)
+					}
+				}
+				isChanged = CHANGED
+			} else {
+				os.Remove(swapName(args[0]))
+			}
+			io.WriteString(out, "\r")
+		}
+		undo.SetJournal(args[0])
+	}
+
+	// rewind moves the cursor to the top of the screen drawn with lf lines.
+	rewind := func(lf int) {
+		if dumb {
+			// print the next screen below instead of overwriting
+			io.WriteString(out, "\r\n")
+			cache = map[int]string{}
+		} else if lf > 0 {
+			fmt.Fprintf(out, "\r\x1B[%dA", lf)
+		} else {
+			io.WriteString(out, "\r")
+		}
+	}
+
+	// setWidth changes the number of bytes per row keeping the cursor
+	// on the same byte and returns the message for the status line.
+	setWidth := func(newSize int) string {
+		if newSize < MIN_LINE_SIZE || newSize > MAX_LINE_SIZE {
+			return fmt.Sprintf("width must be from %d to %d", MIN_LINE_SIZE, MAX_LINE_SIZE)
+		}
+		offset := rowIndex*lineSize + colIndex
+		csrlin := rowIndex - startRow
+		frozen := frozenRow * lineSize
+		buffer.SetLineSize(newSize)
+		rowIndex = offset / lineSize
+		colIndex = offset % lineSize
+		// keep the cursor on the same line of the screen
+		if startRow = rowIndex - csrlin; startRow < 0 {
+			startRow = 0
+		}
+		if frozenRow >= 0 {
+			frozenRow = frozen / lineSize
+		}
+		cache = map[int]string{}
+		return fmt.Sprintf("width: %d bytes", lineSize)
+	}
+
+	var autoSaver *AutoSaver
+	if *flagAutoSave > 0 {
+		fname := *flagOutput
+		if fname == "" {
+			if !localFile {
+				return errors.New("-autosave: requires -o FILE except for one local file without -offset and -length")
+			}
+			fname = autoSaveName(args[0])
+		} else if sliced && isInputFile(fname, args) {
+			return fmt.Errorf("-autosave: %s: can not be overwritten only with the slice of -offset and -length", fname)
+		}
+		autoSaver = NewAutoSaver(fname, *flagAutoSave)
+	}
+
+	// saveState removes the journal and the autosave file and saves the sidecar and the session on quit.
+	// The failures are only told on the standard error since the quit is already confirmed.
+	saveState := func() {
+		undo.RemoveJournal()
+		if autoSaver != nil && *flagOutput == "" {
+			os.Remove(autoSaver.fname)
+		}
+		if localFile {
+			sideCar := &SideCar{
+				Offset: rowIndex*lineSize + colIndex,
+				Marks:  marks,
+			}
+			if err := sideCar.Save(args[0]); err != nil {
+				fmt.Fprintln(os.Stderr, err.Error())
+			}
+		}
+		if session != nil {
+			session.Offset = rowIndex*lineSize + colIndex
+			session.StartRow = startRow
+			session.FrozenRow = frozenRow
+			session.Marks = marks
+			session.Stride = stride
+			session.Store()
+			if err := session.Save(*flagSession); err != nil {
+				fmt.Fprintln(os.Stderr, err.Error())
+			}
+		}
+	}
+
+	var keyReader *KeyReader
+	if (watcher != nil || *flagBlink || autoSaver != nil) && scriptedKeys == nil {
+		keyReader = NewKeyReader()
+	}
+
+	if *flagReserveBottom < 0 {
+		return errors.New("-reserve-bottom: must not be negative")
+	}
+	message, err := guessFileType(buffer)
+	if err != nil {
+		return err
+	}
 	for {
-		screenWidth, screenHeight, err := tty1.Size()
-		if err != nil {
-			return err
+		screenWidth, screenHeight := SCRIPT_WIDTH, SCRIPT_HEIGHT
+		if tty1 != nil {
+			screenWidth, screenHeight, err = tty1.Size()
+			if err != nil {
+				return err
+			}
+		}
+		if *flagCols > 0 {
+			screenWidth = *flagCols
+		}
+		if *flagRows > 0 {
+			screenHeight = *flagRows
+		}
+		// the lines at the bottom are left to the other program
+		screenHeight -= *flagReserveBottom
+		if screenWidth < 2 {
+			screenWidth = 2
+		}
+		if screenHeight < 2 {
+			screenHeight = 2
 		}
 		if lastWidth != screenWidth || lastHeight != screenHeight {
 			cache = map[int]string{}
 			lastWidth = screenWidth
 			lastHeight = screenHeight
 			io.WriteString(out, _ANSI_CURSOR_OFF)
+			if autoWidth {
+				if n := fitLineSize(screenWidth - 1); n != lineSize {
+					setWidth(n)
+				}
+			}
+		}
+		setSelection(visualAnchor, rowIndex*lineSize+colIndex)
+		if cursorLog != nil && rowIndex < buffer.Count() {
+			if err := cursorLog.Write(buffer, rowIndex, colIndex); err != nil {
+				message = err.Error()
+			}
 		}
 		buffer.CursorY = startRow
-		fetch := func() ([]byte, int, error) {
+		fetch := func() ([]byte, int64, error) {
 			return buffer.Fetch()
 		}
-		lf, err := buffer.View(colIndex, rowIndex-startRow, screenWidth-1, screenHeight-1, out)
+		drawHeight, pinned, messageLines := viewRows(screenWidth, screenHeight, message)
+		for i := drawHeight; i < screenHeight; i++ {
+			delete(cache, i)
+		}
+		lf, err := buffer.View(frozenRow, colIndex, rowIndex-startRow, screenWidth-1, drawHeight, out)
 		if err != nil {
 			return err
 		}
-		if buffer.Count() <= 0 {
-			return nil
+		if pinned {
+			io.WriteString(out, "\r\n")
+			lf++
+			pin, err := peekAt(buffer, pinnedOffset)
+			if err != nil {
+				pin = err.Error()
+			}
+			io.WriteString(out, CELL2_COLOR_ON)
+			io.WriteString(out, runewidth.Truncate("[PIN] "+strings.TrimPrefix(pin, "peek "), screenWidth-1, ""))
+			io.WriteString(out, CELL2_COLOR_OFF)
+			io.WriteString(out, ERASE_LINE)
 		}
 		io.WriteString(out, "\r\n") // \r is for Linux & go-tty
 		lf++
-		if message != "" {
+		if messageLines != nil {
+			for i, line := range messageLines {
+				if i > 0 {
+					io.WriteString(out, "\r\n")
+					lf++
+				}
+				io.WriteString(out, _ANSI_YELLOW)
+				io.WriteString(out, line)
+				io.WriteString(out, _ANSI_RESET)
+				io.WriteString(out, ERASE_LINE)
+			}
+			message = ""
+		} else if message != "" {
 			io.WriteString(out, _ANSI_YELLOW)
 			io.WriteString(out, runewidth.Truncate(message, screenWidth-1, ""))
 			io.WriteString(out, _ANSI_RESET)
 			message = ""
+		} else if buffer.Count() <= 0 {
+			io.WriteString(out, _ANSI_YELLOW)
+			io.WriteString(out, runewidth.Truncate("(empty file: i or a to append a byte)", screenWidth-1, ""))
+			io.WriteString(out, _ANSI_RESET)
 		} else if 0 <= rowIndex && rowIndex < buffer.Count() {
 			if 0 <= colIndex && colIndex < buffer.WidthAt(rowIndex) {
-				fmt.Fprintf(out, "\x1B[0;33;1m%[3]c(%08[1]X):0x%02[2]X=%-4[2]d",
-					rowIndex*LINE_SIZE+colIndex,
+				var status strings.Builder
+				offset := int64(rowIndex)*int64(lineSize) + int64(colIndex)
+				address := fmt.Sprintf("%08X", homeAddress+offset)
+				var source string
+				if sources != nil {
+					// the files start at their offsets before -offset skips the data
+					if f, ok := sources.FileAt(homeAddress + offset); ok && fileRelative {
+						address = fmt.Sprintf("%s+%08X", f.Name, homeAddress+offset-f.Offset)
+					} else if ok {
+						source = " [" + f.Name + "]"
+					}
+				}
+				fmt.Fprintf(&status, "%[3]c(%[1]s):0x%02[2]X=%-4[2]d",
+					address,
 					buffer.Byte(rowIndex, colIndex),
 					isChanged)
 
 				theRune, thePosInRune, theLenOfRune := buffer.Rune(rowIndex, colIndex)
-				if theRune != utf8.RuneError {
-					fmt.Fprintf(out, "(%d/%d:U+%X)",
+				if theRune != utf8.RuneError && theLenOfRune > 1 {
+					fmt.Fprintf(&status, "(%d/%d:U+%X %c width:%d)",
+						thePosInRune+1,
+						theLenOfRune,
+						theRune,
+						theRune,
+						runewidth.RuneWidth(theRune))
+				} else if theRune != utf8.RuneError {
+					fmt.Fprintf(&status, "(%d/%d:U+%X)",
 						thePosInRune+1,
 						theLenOfRune,
 						theRune)
 				} else {
-					io.WriteString(out, "(not UTF8)")
+					status.WriteString("(not UTF8)")
+				}
+				status.WriteString(source)
+				if isUnreadable(offset) {
+					status.WriteString(" [UNREADABLE]")
+				}
+				status.WriteString(padding(buffer, int(offset), undo.Changes()))
+				status.WriteString(expectations.Status(buffer, int(offset)))
+				if visualAnchor >= 0 {
+					fmt.Fprintf(&status, " [VISUAL %d bytes]", selectEnd-selectStart+1)
 				}
-				io.WriteString(out, "\x1B[0m")
+				if stride > 0 {
+					fmt.Fprintf(&status, " [STRIDE %d]", stride)
+				}
+				if editMode && editNibble == 0 {
+					io.WriteString(&status, " [EDIT]")
+				} else if editMode {
+					io.WriteString(&status, " [EDIT lower nibble]")
+				}
+				if swapView {
+					io.WriteString(&status, " [SWAP16]")
+				}
+				if autoSaver != nil && !autoSaver.Last.IsZero() {
+					fmt.Fprintf(&status, " [AUTOSAVED %s]", autoSaver.Last.Format("15:04:05"))
+				}
+				if scopeStart >= 0 {
+					fmt.Fprintf(&status, " [SCOPE %08X-%08X]",
+						homeAddress+int64(scopeStart), homeAddress+int64(scopeEnd))
+				}
+				io.WriteString(out, _ANSI_YELLOW)
+				io.WriteString(out, runewidth.Truncate(status.String(), screenWidth-1, ""))
+				io.WriteString(out, _ANSI_RESET)
 			}
 		}
 		io.WriteString(out, ERASE_SCRN_AFTER)
-		ch, err := getkey(tty1)
-		if err != nil {
-			return err
-		}
-		var newByte byte = 0
-		switch ch {
-		case _KEY_CTRL_L:
-			cache = map[int]string{}
-		case "q", _KEY_ESC:
-			if yesNo(tty1, out, "Quit Sure ? [y/n]") {
-				io.WriteString(out, "\n")
-				return nil
+		var ch string
+		if keyReader != nil {
+			interval := WATCH_INTERVAL
+			if *flagBlink {
+				interval = BLINK_INTERVAL
 			}
-		case "j", _KEY_DOWN, _KEY_CTRL_N:
-			if rowIndex < buffer.Count()-1 {
-				rowIndex++
+			var ok bool
+			ch, ok, err = keyReader.Wait(tty1, interval)
+			if err != nil {
+				return err
+			}
+			if autoSaver != nil {
+				if saved, err := autoSaver.Save(buffer, undo); err != nil {
+					message = "autosave: " + err.Error()
+				} else if saved && autoSaver.fname == *flagOutput {
+					// the same as w
+					isChanged = UNCHANGED
+					undo.ResetJournal(autoSaver.fname)
+				}
+			}
+			if !ok {
+				if *flagBlink {
+					cursorBlinkOff = !cursorBlinkOff
+				}
+				if watcher != nil {
+					// The file being rewritten may be missing for a moment.
+					if changed, err := watcher.Changed(); err == nil && changed {
+						if isChanged == CHANGED {
+							message = args[0] + ": changed on disk (not reloaded because of the edits)"
+						} else {
+							offset := rowIndex*lineSize + colIndex
+							pin.Close()
+							unreadable = nil
+							pin, err = openInput(args)
+							if err != nil {
+								return err
+							}
+							buffer = NewBuffer(pin)
+							buffer.Progress = progress
+							rowIndex, colIndex, err = seekOffset(buffer, offset)
+							if err != nil {
+								return err
+							}
+							if startRow > rowIndex {
+								startRow = rowIndex
+							}
+							undo.log = undo.log[:0]
+							cache = map[int]string{}
+							message = args[0] + ": reloaded"
+						}
+					}
+				}
+				rewind(lf)
+				continue
+			}
+			cursorBlinkOff = false
+		} else {
+			ch, err = getkey(tty1)
+			if err == errEndOfKeys {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+		}
+		if buffer.Count() <= 0 {
+			// keys which do not need any data
+			switch ch {
+			case _KEY_CTRL_L, "q", _KEY_ESC, "i", "a", "p", "P", "u", "g", "w", "W", "S":
+			default:
+				ch = ""
+			}
+		}
+		foundStart, foundEnd = -1, -1
+		if editMode {
+			if n, err := strconv.ParseUint(ch, 16, 8); err == nil && len(ch) == 1 && buffer.Count() > 0 {
+				old := buffer.Byte(rowIndex, colIndex)
+				new := old&0xF0 | byte(n)
+				if editNibble == 0 {
+					new = byte(n)<<4 | old&0x0F
+				}
+				undo.Push(Edit{
+					Offset: rowIndex*lineSize + colIndex,
+					Old:    []byte{old},
+					New:    []byte{new},
+				})
+				buffer.SetByte(rowIndex, colIndex, new)
+				isChanged = CHANGED
+				if editNibble == 0 {
+					editNibble = 1
+					ch = ""
+				} else {
+					editNibble = 0
+					ch = "l"
+				}
+			} else if ch == _KEY_ESC {
+				editMode = false
+				editNibble = 0
+				message = "edit mode: off"
+				ch = ""
+			} else {
+				editNibble = 0
+			}
+		}
+		var newByte byte = 0
+		switch ch {
+		case _KEY_CTRL_L:
+			cache = map[int]string{}
+		case "q", _KEY_ESC:
+			if visualAnchor >= 0 {
+				visualAnchor = -1
+				break
+			}
+			if (*flagNoConfirm && isChanged == UNCHANGED) || yesNo(tty1, out, "Quit Sure ? [y/n]") {
+				if altScreen {
+					// the previous contents of the terminal come back
+				} else if *flagOnExit == "keep" {
+					io.WriteString(out, "\n")
+				} else {
+					fmt.Fprintf(out, "\r\x1B[%dA%s%s", lf, ERASE_SCRN_AFTER, _ANSI_RESET)
+				}
+				if *flagOnExit == "plain" && !altScreen {
+					top, rows := startRow, screenHeight-1
+					if *flagExitRows > 0 {
+						rows = *flagExitRows
+						if err := buffer.ReadUntil(rowIndex + rows); err != nil {
+							return err
+						}
+						top = rowIndex - rows/2
+						if top+rows > buffer.Count() {
+							top = buffer.Count() - rows
+						}
+						if top < 0 {
+							top = 0
+						}
+					}
+					lines, err := buffer.snapshot(top, frozenRow, -1, -1, screenWidth-1, rows)
+					if err != nil {
+						return err
+					}
+					for _, line := range lines {
+						io.WriteString(out, stripEscapes(line))
+						io.WriteString(out, "\r\n")
+					}
+				}
+				saveState()
+				return nil
+			}
+		case "Q":
+			if pipeOut == nil {
+				message = "Q: the standard output is not a pipe"
+				break
+			}
+			if visualAnchor < 0 {
+				message = "Q: not in visual mode"
+				break
+			}
+			fmt.Fprintf(out, "\r\x1B[%dA%s%s", lf, ERASE_SCRN_AFTER, _ANSI_RESET)
+			if _, err := pipeOut.Write(copyRange(buffer, selectStart, selectEnd)); err != nil {
+				return err
+			}
+			saveState()
+			return nil
+		case "j", _KEY_DOWN, _KEY_CTRL_N:
+			if *flagSqueeze {
+				rowIndex, err = nextSqueezedRow(buffer, rowIndex)
+				if err != nil {
+					return err
+				}
+			} else if rowIndex < buffer.Count()-1 {
+				rowIndex++
 			} else if _, _, err := fetch(); err == nil {
 				rowIndex++
 			} else if err != io.EOF {
 				return err
 			}
 		case "k", _KEY_UP, _KEY_CTRL_P:
-			if rowIndex > 0 {
+			if *flagSqueeze {
+				rowIndex = prevSqueezedRow(buffer, rowIndex)
+			} else if rowIndex > 0 {
 				rowIndex--
 			}
 		case "h", "\b", _KEY_LEFT, _KEY_CTRL_B:
@@ -296,10 +1155,10 @@ func mains(args []string) error {
 				colIndex--
 			} else if rowIndex > 0 {
 				rowIndex--
-				colIndex = LINE_SIZE - 1
+				colIndex = lineSize - 1
 			}
 		case "l", " ", _KEY_RIGHT, _KEY_CTRL_F:
-			if colIndex < LINE_SIZE-1 {
+			if colIndex < lineSize-1 {
 				colIndex++
 			} else if rowIndex < buffer.Count()-1 {
 				rowIndex++
@@ -314,6 +1173,7 @@ func mains(args []string) error {
 			colIndex = 0
 		case "$", _KEY_CTRL_E:
 			colIndex = buffer.WidthAt(rowIndex) - 1
+			stickyEnd = *flagStickyEnd
 		case "<":
 			rowIndex = 0
 			colIndex = 0
@@ -329,14 +1189,19 @@ func mains(args []string) error {
 			newByte = clipBoard.Pop()
 			fallthrough
 		case "a":
-			appendOne(buffer, rowIndex, colIndex)
-			if colIndex+1 < len(buffer.Slices[rowIndex]) {
-				colIndex++
+			if buffer.Count() <= 0 {
+				buffer.Add([]byte{newByte})
 			} else {
-				colIndex = 0
-				rowIndex++
+				appendOne(buffer, rowIndex, colIndex)
+				if colIndex+1 < len(buffer.Slices[rowIndex]) {
+					colIndex++
+				} else {
+					colIndex = 0
+					rowIndex++
+				}
+				buffer.Slices[rowIndex][colIndex] = newByte
 			}
-			buffer.Slices[rowIndex][colIndex] = newByte
+			undo.Push(Edit{Offset: rowIndex*lineSize + colIndex, New: []byte{newByte}})
 			isChanged = CHANGED
 		case "P":
 			if clipBoard.Len() <= 0 {
@@ -345,20 +1210,776 @@ func mains(args []string) error {
 			newByte = clipBoard.Pop()
 			fallthrough
 		case "i":
-			insertOne(buffer, rowIndex, colIndex)
-			buffer.Slices[rowIndex][colIndex] = newByte
+			if buffer.Count() <= 0 {
+				buffer.Add([]byte{newByte})
+			} else {
+				insertOne(buffer, rowIndex, colIndex)
+				buffer.Slices[rowIndex][colIndex] = newByte
+			}
+			undo.Push(Edit{Offset: rowIndex*lineSize + colIndex, New: []byte{newByte}})
 			isChanged = CHANGED
 		case "x", _KEY_DEL:
-			clipBoard.Push(buffer.Slices[rowIndex][colIndex])
+			deleteByte := buffer.Slices[rowIndex][colIndex]
+			clipBoard.Push(deleteByte)
 			deleteOne(buffer, rowIndex, colIndex)
+			undo.Push(Edit{Offset: rowIndex*lineSize + colIndex, Old: []byte{deleteByte}})
+			isChanged = CHANGED
+		case "u":
+			if offset := undo.Pop(buffer); offset >= 0 {
+				rowIndex = offset / lineSize
+				colIndex = offset % lineSize
+				isChanged = CHANGED
+			} else {
+				message = "no more undo"
+			}
+		case "v":
+			if visualAnchor >= 0 {
+				visualAnchor = -1
+			} else {
+				visualAnchor = rowIndex*lineSize + colIndex
+			}
+		case "V":
+			if visualAnchor >= 0 {
+				scopeStart, scopeEnd = selectStart, selectEnd
+				visualAnchor = -1
+				message = fmt.Sprintf("search scope: %d bytes", scopeEnd-scopeStart+1)
+			} else if scopeStart >= 0 {
+				scopeStart, scopeEnd = -1, -1
+				message = "search scope: the whole data"
+			} else {
+				message = "V: select the scope in visual mode"
+			}
+		case "E":
+			if visualAnchor < 0 {
+				message = "E: not in visual mode"
+				break
+			}
+			edit, msg := reverseSelection(buffer)
+			undo.Push(edit)
+			isChanged = CHANGED
+			message = msg
+			visualAnchor = -1
+		case "~":
+			if visualAnchor < 0 {
+				message = "~: not in visual mode"
+				break
+			}
+			edit, msg := invertSelection(buffer)
+			undo.Push(edit)
+			isChanged = CHANGED
+			message = msg
+			visualAnchor = -1
+		case "e":
+			if visualAnchor < 0 {
+				message = "e: not in visual mode"
+				break
+			}
+			edit, msg, err := swapSelection(buffer)
+			if err != nil {
+				message = err.Error()
+				break
+			}
+			undo.Push(edit)
+			isChanged = CHANGED
+			message = msg
+			visualAnchor = -1
+		case "B":
+			old := buffer.Byte(rowIndex, colIndex)
+			new := old<<4 | old>>4
+			undo.Push(Edit{
+				Offset: rowIndex*lineSize + colIndex,
+				Old:    []byte{old},
+				New:    []byte{new},
+			})
+			buffer.SetByte(rowIndex, colIndex, new)
+			isChanged = CHANGED
+			message = fmt.Sprintf("swapped the nibbles: 0x%02X -> 0x%02X", old, new)
+		case "o":
+			editMode = true
+			editNibble = 0
+			message = "edit mode: type the hex digits to overwrite (ESCAPE to quit the mode)"
+		case "t":
+			offset := rowIndex*lineSize + colIndex
+			var defaultStr string
+			if c, ok := expectations[offset]; ok {
+				defaultStr = fmt.Sprintf("%02X", c)
+			}
+			str, err := getline(out, "expect(hex)>", defaultStr)
+			if err != nil {
+				message = err.Error()
+				break
+			}
+			var values []byte
+			if strings.TrimSpace(str) != "" {
+				if values, err = parseHexBytes(str); err != nil {
+					message = err.Error()
+					break
+				}
+			}
+			message = expectations.Set(offset, values)
+		case "f":
+			if offset := rowIndex*lineSize + colIndex; pinnedOffset != offset {
+				pinnedOffset = offset
+				message = fmt.Sprintf("pinned 0x%08X (f again to unpin)", homeAddress+int64(offset))
+			} else {
+				pinnedOffset = -1
+				message = "unpinned"
+			}
+		case "b":
+			swapView = !swapView
+			if swapView {
+				message = "swap view: every pair of bytes is shown swapped"
+			} else {
+				message = "swap view: off"
+			}
+		case "D":
+			if visualAnchor < 0 {
+				message = "D: not in visual mode"
+				break
+			}
+			edit, msg := duplicateSelection(buffer)
+			undo.Push(edit)
 			isChanged = CHANGED
-		case "w":
-			if err := write(buffer, tty1, out, args); err != nil {
+			message = msg
+			visualAnchor = -1
+			rowIndex, colIndex, err = seekOffset(buffer, edit.Offset)
+			if err != nil {
+				return err
+			}
+		case "!":
+			if visualAnchor < 0 {
+				message = "!: not in visual mode"
+				break
+			}
+			cmdline, err := getline(out, "filter>", "")
+			if err != nil {
+				message = err.Error()
+				break
+			}
+			edit, msg, err := filterSelection(buffer, cmdline)
+			if err != nil {
+				message = err.Error()
+				break
+			}
+			undo.Push(edit)
+			isChanged = CHANGED
+			message = msg
+			visualAnchor = -1
+			if buffer.Count() <= 0 {
+				rowIndex, colIndex = 0, 0
+				break
+			}
+			rowIndex, colIndex, err = seekOffset(buffer, edit.Offset)
+			if err != nil {
+				return err
+			}
+		case "+", "-":
+			newSize := lineSize + 1
+			if ch == "-" {
+				newSize = lineSize - 1
+			}
+			autoWidth = false
+			message = setWidth(newSize)
+		case ":":
+			line, err := getline(out, ":", "")
+			if err != nil {
+				message = err.Error()
+				break
+			}
+			if n, ok, err := parseBenchCommand(line); ok {
+				if err != nil {
+					message = err.Error()
+					break
+				}
+				frame, err := buffer.benchView(startRow, frozenRow, colIndex, rowIndex-startRow, screenWidth-1, screenHeight-1, n)
+				if err != nil {
+					return err
+				}
+				message = fmt.Sprintf("bench: %d ns/frame (%d frames)", frame.Nanoseconds(), n)
+				break
+			}
+			name, value, err := parseSetCommand(line)
+			if err != nil {
+				message = err.Error()
+				break
+			}
+			if name == "width" {
+				n, err := strconv.Atoi(value)
+				if err != nil {
+					message = fmt.Sprintf("width: %s: not a number", value)
+					break
+				}
+				autoWidth = false
+				message = setWidth(n)
+				break
+			}
+			if message, err = setOption(name, value); err != nil {
+				message = err.Error()
+			}
+			cache = map[int]string{}
+		case "m":
+			name, err := askKey(tty1, out, "mark name [a-z] ?")
+			if err != nil {
+				return err
+			}
+			if !isMarkName(name) {
+				message = fmt.Sprintf("%q: invalid mark name", name)
+				break
+			}
+			marks[name] = rowIndex*lineSize + colIndex
+			message = fmt.Sprintf("mark '%s at 0x%08X", name, homeAddress+int64(marks[name]))
+		case "'":
+			name, err := askKey(tty1, out, "jump to mark [a-z] ?")
+			if err != nil {
+				return err
+			}
+			offset, err := marks.Get(name)
+			if err != nil {
+				message = err.Error()
+				break
+			}
+			rowIndex, colIndex, err = seekOffset(buffer, offset)
+			if err != nil {
+				return err
+			}
+		case "M":
+			from, err := askKey(tty1, out, "distance from mark [a-z] ?")
+			if err != nil {
+				return err
+			}
+			to, err := askKey(tty1, out, "distance from mark '"+from+" to mark [a-z] ?")
+			if err != nil {
+				return err
+			}
+			if message, err = marks.Distance(from, to); err != nil {
+				message = err.Error()
+			}
+		case "|":
+			compareAbove = !compareAbove
+			if compareAbove {
+				message = "highlight the bytes different from the row above"
+			} else {
+				message = "no highlight of the bytes different from the row above"
+			}
+		case "c":
+			byteClassColor = !byteClassColor
+			if byteClassColor {
+				message = "color: by the class of the byte"
+			} else {
+				message = "color: by the position"
+			}
+		case "H":
+			str, err := getline(out, "highlight(hex)>", "")
+			if err != nil {
+				message = err.Error()
+				break
+			}
+			pattern, err := parseHexBytes(str)
+			if err != nil {
+				message = err.Error()
+				break
+			}
+			n, err := addHighlight(pattern)
+			if err != nil {
+				message = err.Error()
+				break
+			}
+			message = fmt.Sprintf("highlight %d: % X (toggle it with %d)", n, pattern, n)
+		case "1", "2", "3", "4", "5", "6", "7", "8", "9":
+			n := int(ch[0] - '0')
+			if n > len(highlights) {
+				message = fmt.Sprintf("%d: no highlight (add it with H)", n)
+				break
+			}
+			h := highlights[n-1]
+			h.Enabled = !h.Enabled
+			if h.Enabled {
+				message = fmt.Sprintf("highlight %d: % X on", n, h.Pattern)
+			} else {
+				message = fmt.Sprintf("highlight %d: % X off", n, h.Pattern)
+			}
+		case "C":
+			index, err := listBox(tty1, out, "charset", charsets, screenWidth-1, lf)
+			cache = map[int]string{}
+			if err != nil {
+				return err
+			}
+			if index < 0 {
+				break
+			}
+			setCharset(charsets[index])
+			message = "charset: " + charset
+		case "`":
+			if previousCharset == "" {
+				message = "no previous charset: select it with C"
+				break
+			}
+			setCharset(previousCharset)
+			message = "charset: " + charset
+		case "d":
+			if *flagMode == "int8" {
+				*flagMode = "hex"
+			} else {
+				*flagMode = "int8"
+			}
+			cache = map[int]string{}
+			message = "mode: " + *flagMode
+		case "L":
+			showRowNumber = !showRowNumber
+			if showRowNumber {
+				message = "row number: shown"
+			} else {
+				message = "row number: hidden"
+			}
+		case "@":
+			relativeAddress = !relativeAddress
+			if relativeAddress {
+				message = "address: relative to the top of the screen"
+			} else {
+				message = "address: absolute"
+			}
+		case "I":
+			imagePreview = !imagePreview
+			if imagePreview {
+				message = fmt.Sprintf("image preview: %d pixels per row (change with + and -)", lineSize)
+			} else {
+				message = "image preview: off"
+			}
+		case "T":
+			if sources == nil {
+				message = "T: just one file"
+				break
+			}
+			fileRelative = !fileRelative
+			if fileRelative {
+				message = "status line address: relative to the file"
+			} else {
+				message = "status line address: in the concatenated data"
+			}
+		case "A":
+			decimalAddress = !decimalAddress
+			if decimalAddress {
+				message = "address: hex and decimal"
+			} else {
+				message = "address: hex"
+			}
+		case "Z":
+			endAddress = !endAddress
+			if endAddress {
+				message = "address: with the offset from the end"
+			} else {
+				message = "address: from the top"
+			}
+		case "]", "[":
+			var start, end int
+			var ok bool
+			if ch == "]" {
+				start, end, ok = nextString(buffer, rowIndex*lineSize+colIndex, *flagStringsMin)
+			} else {
+				start, end, ok = prevString(buffer, rowIndex*lineSize+colIndex, *flagStringsMin)
+			}
+			if !ok {
+				message = "no more strings"
+				break
+			}
+			foundStart, foundEnd = start, end
+			rowIndex, colIndex, err = seekOffset(buffer, start)
+			if err != nil {
+				return err
+			}
+			message = fmt.Sprintf("string at 0x%08X (%d bytes)", homeAddress+int64(start), end-start+1)
+		case "(", ")":
+			start, end, err := stringAround(buffer, rowIndex*lineSize+colIndex)
+			if err != nil {
+				message = err.Error()
+				break
+			}
+			foundStart, foundEnd = start, end
+			offset := start
+			if ch == ")" {
+				offset = end
+			}
+			rowIndex, colIndex, err = seekOffset(buffer, offset)
+			if err != nil {
+				return err
+			}
+			message = fmt.Sprintf("string at 0x%08X (%d bytes)", homeAddress+int64(start), end-start+1)
+		case "s":
+			runs := allStrings(buffer, *flagStringsMin)
+			if len(runs) <= 0 {
+				message = "no strings"
+				break
+			}
+			items := make([]string, len(runs))
+			for i, r := range runs {
+				items[i] = fmt.Sprintf("%08X %s", homeAddress+int64(r.Start), copyRange(buffer, r.Start, r.End))
+			}
+			index, err := listBox(tty1, out, "strings", items, screenWidth-1, lf)
+			cache = map[int]string{}
+			if err != nil {
+				return err
+			}
+			if index < 0 {
+				break
+			}
+			foundStart, foundEnd = runs[index].Start, runs[index].End
+			rowIndex, colIndex, err = seekOffset(buffer, foundStart)
+			if err != nil {
+				return err
+			}
+		case "J":
+			if *flagRecord <= 0 {
+				message = "J: set the size of the records with -record"
+				break
+			}
+			offsets, items := recordList(buffer, fields)
+			if len(items) <= 0 {
+				message = "no records"
+				break
+			}
+			index, err := listBox(tty1, out, "records", items, screenWidth-1, lf)
+			cache = map[int]string{}
+			if err != nil {
+				return err
+			}
+			if index < 0 {
+				break
+			}
+			rowIndex, colIndex, err = seekOffset(buffer, offsets[index])
+			if err != nil {
+				return err
+			}
+			message = fmt.Sprintf("record #%d", index)
+		case "K":
+			names := marks.Names()
+			if len(names) <= 0 {
+				message = "no marks: set them with m"
+				break
+			}
+			items := make([]string, len(names))
+			for i, name := range names {
+				if items[i], err = marks.listItem(buffer, name); err != nil {
+					return err
+				}
+			}
+			index, err := listBox(tty1, out, "marks", items, screenWidth-1, lf)
+			cache = map[int]string{}
+			if err != nil {
+				return err
+			}
+			if index < 0 {
+				break
+			}
+			rowIndex, colIndex, err = seekOffset(buffer, marks[names[index]])
+			if err != nil {
+				return err
+			}
+		case "Y":
+			if message, err = copyAddress(rowIndex*lineSize + colIndex); err != nil {
+				message = err.Error()
+			}
+		case _KEY_CTRL_Y:
+			if message, err = copyRow(buffer, rowIndex); err != nil {
+				message = err.Error()
+			}
+		case _KEY_CTRL_V:
+			offset, err := pastedOffset(buffer, rowIndex*lineSize+colIndex)
+			if err != nil {
+				message = err.Error()
+				break
+			}
+			rowIndex, colIndex, err = seekOffset(buffer, offset)
+			if err != nil {
+				return err
+			}
+			message = fmt.Sprintf("goto 0x%08X", homeAddress+int64(rowIndex)*int64(lineSize)+int64(colIndex))
+		case "y":
+			start, end := rowIndex*lineSize+colIndex, rowIndex*lineSize+colIndex
+			if visualAnchor >= 0 {
+				start, end = selectStart, selectEnd
+				visualAnchor = -1
+			}
+			if message, err = copyValue(buffer, start, end); err != nil {
+				message = err.Error()
+			}
+		case "=":
+			switch wordPaneSize {
+			case 0:
+				wordPaneSize = 2
+			case 2:
+				wordPaneSize = 4
+			case 4:
+				wordPaneSize = 8
+			default:
+				wordPaneSize = 0
+			}
+			if wordPaneSize > 0 {
+				message = fmt.Sprintf("word pane: %d bytes per word", wordPaneSize)
+			} else {
+				message = "word pane: off"
+			}
+		case "F":
+			if frozenRow == rowIndex {
+				frozenRow = -1
+				message = "unfreeze the header row"
+			} else {
+				frozenRow = rowIndex
+				message = fmt.Sprintf("freeze the row at 0x%08X as the header", homeAddress+int64(rowIndex)*int64(lineSize))
+			}
+			cache = map[int]string{}
+		case "S":
+			fname, err := getline(out, "snapshot to>", "binview.html")
+			if err != nil {
+				message = err.Error()
+				break
+			}
+			lines, err := buffer.snapshot(startRow, frozenRow, colIndex, rowIndex-startRow, screenWidth-1, screenHeight-1)
+			if err != nil {
+				return err
+			}
+			if err := writeHTML(fname, lines); err != nil {
+				message = err.Error()
+			} else {
+				message = "saved the snapshot as " + fname
+			}
+		case "O":
+			if baseline == nil {
+				message = "O: no baseline: start with -base FILE"
+				break
+			}
+			fname, err := getline(out, "patch to>", "binview.patch")
+			if err != nil {
+				message = err.Error()
+				break
+			}
+			if n, err := writePatch(fname, buffer); err != nil {
+				message = err.Error()
+			} else {
+				message = fmt.Sprintf("wrote %d different bytes to %s", n, fname)
+			}
+		case "#":
+			str, err := getline(out, "stride(bytes)>", "")
+			if err != nil {
+				message = err.Error()
+				break
+			}
+			n, err := strconv.ParseUint(strings.TrimSpace(str), 0, 0)
+			if err != nil {
+				message = err.Error()
+				break
+			}
+			stride = int(n)
+			message = fmt.Sprintf("stride: %d bytes", stride)
+		case "}", "{":
+			if stride <= 0 {
+				message = "no stride: set it with #"
+				break
+			}
+			offset := rowIndex*lineSize + colIndex
+			if ch == "}" {
+				offset += stride
+			} else {
+				offset -= stride
+			}
+			row, col, err := seekOffset(buffer, offset)
+			if err != nil {
+				return err
+			}
+			if offset < 0 || row*lineSize+col != offset {
+				message = "no more records"
+				break
+			}
+			rowIndex, colIndex = row, col
+		case "*":
+			size := 4
+			if wordPaneSize > 0 {
+				size = wordPaneSize
+			}
+			mode, err := askKey(tty1, out,
+				fmt.Sprintf("follow the %d-byte pointer as [a]bsolute, [o]ffset from the top or [s]elf-relative, or the text number in [d]ecimal, [8]octal or he[x] ?", size))
+			if err != nil {
+				return err
+			}
+			target, err := pointerTarget(buffer, rowIndex*lineSize+colIndex, size, mode)
+			if err != nil {
+				message = err.Error()
+				break
+			}
+			if !yesNo(tty1, out, fmt.Sprintf("jump to 0x%08X ? [y/n]", homeAddress+int64(target))) {
+				if message, err = peekAt(buffer, target); err != nil {
+					message = err.Error()
+				}
+				break
+			}
+			if target < 0 {
+				message = fmt.Sprintf("0x%08X: out of the data", homeAddress+int64(target))
+				break
+			}
+			rowIndex, colIndex, err = seekOffset(buffer, target)
+			if err != nil {
+				return err
+			}
+			if rowIndex*lineSize+colIndex != target {
+				message = fmt.Sprintf("0x%08X: out of the data", homeAddress+int64(target))
+			}
+		case "X":
+			tool := *flagTool
+			if tool == "" {
+				tool = os.Getenv("BINVIEW_TOOL")
+			}
+			if tool == "" {
+				message = "X: no tool: set -tool or BINVIEW_TOOL"
+				break
+			}
+			if len(args) != 1 {
+				message = "X: just one file is required"
+				break
+			}
+			fmt.Fprintf(out, "\r\x1B[%dA%s%s%s", lf, ERASE_SCRN_AFTER, _ANSI_RESET, _ANSI_CURSOR_ON)
+			err := runTool(tool, args[0], homeAddress+int64(rowIndex)*int64(lineSize)+int64(colIndex))
+			if err != nil {
+				fmt.Fprintf(out, "%s\r\n", err.Error())
+			}
+			if _, err := askKey(tty1, out, "Hit any key to return to binview"); err != nil {
+				return err
+			}
+			io.WriteString(out, _ANSI_RESET+_ANSI_CURSOR_OFF+strings.Repeat("\r\n", lf))
+			cache = map[int]string{}
+		case "/", "n", "N":
+			if ch == "/" {
+				str, err := getline(out, "search(hex, \"text or u32le N)>", "")
+				if err != nil {
+					message = err.Error()
+					break
+				}
+				pattern, mask, err := parseSearchPattern(str)
+				if err != nil {
+					message = err.Error()
+					break
+				}
+				lastPattern, lastMask = pattern, mask
+			} else if lastPattern == nil {
+				message = "no previous pattern: search with /"
+				break
+			}
+			var found int
+			var wrapped, ok bool
+			if ch == "N" {
+				found, wrapped, ok = searchBackward(buffer, rowIndex*lineSize+colIndex, lastPattern, lastMask)
+			} else {
+				found, wrapped, ok = searchForward(buffer, rowIndex*lineSize+colIndex, lastPattern, lastMask)
+			}
+			if !ok {
+				message = fmt.Sprintf("% X: not found", lastPattern)
+				break
+			}
+			foundStart, foundEnd = found, found+len(lastPattern)-1
+			rowIndex, colIndex, err = seekOffset(buffer, found)
+			if err != nil {
+				return err
+			}
+			if wrapped && ch == "N" {
+				message = "search hit TOP, continuing at BOTTOM"
+			} else if wrapped {
+				message = "search hit BOTTOM, continuing at TOP"
+			}
+		case "%":
+			str, err := getline(out, "previous boundary of N (+N: next)>", "")
+			if err != nil {
+				message = err.Error()
+				break
+			}
+			str = strings.TrimSpace(str)
+			next := strings.HasPrefix(str, "+")
+			n, err := strconv.ParseUint(strings.TrimPrefix(str, "+"), 0, 0)
+			if err != nil {
+				message = err.Error()
+				break
+			}
+			if n <= 0 {
+				message = "align: must be 1 or more"
+				break
+			}
+			offset := alignOffset(rowIndex*lineSize+colIndex, int(n), next)
+			rowIndex, colIndex, err = seekOffset(buffer, offset)
+			if err != nil {
+				return err
+			}
+			if rowIndex*lineSize+colIndex != offset {
+				message = fmt.Sprintf("0x%08X: out of the data", homeAddress+int64(offset))
+				break
+			}
+			message = fmt.Sprintf("aligned to 0x%08X", homeAddress+int64(offset))
+		case "&":
+			str, err := getline(out, "peek>", "")
+			if err != nil {
+				message = err.Error()
+				break
+			}
+			offset, err := parseAddress(buffer, str, rowIndex*lineSize+colIndex)
+			if err != nil {
+				message = err.Error()
+				break
+			}
+			if message, err = peekAt(buffer, offset); err != nil {
+				message = err.Error()
+			}
+		case "g":
+			str, err := getline(out, "goto>", "")
+			if err != nil {
+				message = err.Error()
+				break
+			}
+			offset, err := parseAddress(buffer, str, rowIndex*lineSize+colIndex)
+			if err != nil {
+				message = err.Error()
+				break
+			}
+			rowIndex, colIndex, err = seekOffset(buffer, offset)
+			if err != nil {
+				return err
+			}
+			message = fmt.Sprintf("goto 0x%08X", homeAddress+int64(rowIndex)*int64(lineSize)+int64(colIndex))
+		case "w", "W":
+			fname := *flagOutput
+			if fname == "" || ch == "W" {
+				argf, ok := argfOf(pin)
+				fname, err = askOutputName(out, args, ok && argf.Compressed, sliced)
+				if err != nil {
+					message = err.Error()
+					break
+				}
+			}
+			if sliced && isInputFile(fname, args) {
+				message = fname + ": can not be overwritten only with the slice of -offset and -length"
+				break
+			}
+			if err := write(buffer, tty1, out, fname); err != nil {
 				message = err.Error()
 			} else {
 				isChanged = UNCHANGED
+				message = "wrote to " + fname
+				undo.ResetJournal(fname)
 			}
 		case "r":
+			if visualAnchor >= 0 {
+				str, err := getline(out, "fill(hex)>", "")
+				if err != nil {
+					message = err.Error()
+					break
+				}
+				pattern, err := parseHexBytes(str)
+				if err != nil {
+					message = err.Error()
+					break
+				}
+				edit, msg := fillSelection(buffer, pattern)
+				undo.Push(edit)
+				isChanged = CHANGED
+				message = msg
+				visualAnchor = -1
+				break
+			}
 			bytes, err := getline(out, "replace>",
 				fmt.Sprintf("0x%02X", buffer.Byte(rowIndex, colIndex)))
 			if err != nil {
@@ -366,38 +1987,188 @@ func mains(args []string) error {
 				break
 			}
 			if n, err := strconv.ParseUint(bytes, 0, 8); err == nil {
+				undo.Push(Edit{
+					Offset: rowIndex*lineSize + colIndex,
+					Old:    []byte{buffer.Byte(rowIndex, colIndex)},
+					New:    []byte{byte(n)},
+				})
 				buffer.SetByte(rowIndex, colIndex, byte(n))
 				isChanged = CHANGED
 			} else {
 				message = err.Error()
 			}
+		case "R":
+			from, to, err := getReplacePatterns(out)
+			if err != nil {
+				message = err.Error()
+				break
+			}
+			edits := replaceAll(buffer, from, to)
+			if len(edits) > 0 {
+				undo.Push(edits...)
+				isChanged = CHANGED
+			}
+			message = fmt.Sprintf("replaced %d matches", len(edits))
 		}
-		if buffer.Count() <= 0 {
-			return nil
-		}
-		if rowIndex >= buffer.Count() {
-			rowIndex--
-			colIndex = LINE_SIZE
+		if stickyEnd {
+			switch ch {
+			case "$", _KEY_CTRL_E, "j", _KEY_DOWN, _KEY_CTRL_N, "k", _KEY_UP, _KEY_CTRL_P:
+				if rowIndex < buffer.Count() {
+					colIndex = buffer.WidthAt(rowIndex) - 1
+				}
+			default:
+				stickyEnd = false
+			}
 		}
-		if colIndex >= buffer.WidthAt(rowIndex) {
-			colIndex = buffer.WidthAt(rowIndex) - 1
+		rowIndex, colIndex = buffer.Clamp(rowIndex, colIndex)
+		if err := undo.Err(); err != nil {
+			message = err.Error()
 		}
 
-		if rowIndex < startRow {
-			startRow = rowIndex
-		} else if rowIndex >= startRow+screenHeight-1 {
-			startRow = rowIndex - (screenHeight - 1) + 1
-		}
-		if lf > 0 {
-			fmt.Fprintf(out, "\r\x1B[%dA", lf)
-		} else {
-			io.WriteString(out, "\r")
+		// The frozen row takes a row only when it is scrolled out as View draws it.
+		// When the scroll below makes it shown, scroll again with one row less.
+		for {
+			viewHeight, _, _ := viewRows(screenWidth, screenHeight, message)
+			frozenShown := 0 <= frozenRow && frozenRow < startRow && viewHeight > 1
+			if frozenShown {
+				viewHeight--
+			}
+			scrollOff := *flagScrollOff
+			if scrollOff*2 >= viewHeight {
+				scrollOff = (viewHeight - 1) / 2
+			}
+			if rowIndex-scrollOff < startRow {
+				startRow = rowIndex - scrollOff
+				if startRow < 0 {
+					startRow = 0
+				}
+			} else if *flagSqueeze {
+				bottom := rowIndex + scrollOff
+				if bottom >= buffer.Count() {
+					bottom = buffer.Count() - 1
+				}
+				startRow = squeezedTop(buffer, startRow, bottom, viewHeight)
+			} else if rowIndex+scrollOff >= startRow+viewHeight {
+				startRow = rowIndex + scrollOff - viewHeight + 1
+				if scrollOff > 0 && buffer.Reader == nil && startRow > buffer.Count()-viewHeight {
+					startRow = buffer.Count() - viewHeight
+					if startRow < 0 {
+						startRow = 0
+					}
+				}
+			}
+			if !*flagSqueeze && (*flagHeader > 0 || *flagRecord > 0) {
+				bottom := rowIndex + scrollOff
+				if bottom >= buffer.Count() {
+					bottom = buffer.Count() - 1
+				}
+				startRow = separatedTop(startRow, bottom, viewHeight)
+			}
+			if frozenShown || !(0 <= frozenRow && frozenRow < startRow && viewHeight > 1) {
+				break
+			}
 		}
+		rewind(lf)
 	}
 }
 
+var flagGoto = flag.String("goto", "", "the offset where the cursor is put on startup")
+
+var flagOnExit = flag.String("on-exit", "keep", "what to do with the last screen on quit: keep, plain or erase")
+
+var flagOutput = flag.String("o", "", "the file name which w writes to")
+
+var flagSqueeze = flag.Bool("squeeze", false, "show a line of * instead of the rows same as the previous row")
+
+var flagGroup = flag.Int("group", 4, "the number of bytes per group")
+
+var flagGroupSep = flag.String("group-sep", " ", "the character between groups")
+
+var flagStringsMin = flag.Int("strings-min", 4, "the minimal length of strings which ] and [ jump to")
+
+var flagPid = flag.Int("pid", 0, "the process id to view its memory via /proc/PID/mem (Linux only)")
+
+var flagAddress = flag.String("addr", "0", "the address of the memory to view with -pid")
+
+var flagLength = flag.Int64("length", 0, "the number of bytes to read (0: until the end of the data or the mapping with -pid)")
+
+var flagOffset = flag.Int64("offset", 0, "skip the first N bytes of the data (the addresses start from N)")
+
+var flagWordPane = flag.Int("word-pane", 0, "the bytes per word of the second pane (2, 4 or 8. 0: hidden)")
+
+var flagCols = flag.Int("cols", 0, "the width of the terminal instead of the detected one")
+
+var flagRows = flag.Int("rows", 0, "the height of the terminal instead of the detected one")
+
+var flagHeader = flag.Int("header", 0, "the size of the header to draw a line after it")
+
+var flagRecord = flag.Int("record", 0, "the size of the records after the header to draw a line between them")
+
+var flagBlink = flag.Bool("blink", false, "blink the cursor cell")
+
+var flagCharset = flag.String("charset", "utf8", "the charset of the text pane: utf8, latin1 or ascii")
+
+var flagSession = flag.String("session", "", "the file to restore the view from at startup and to save it to on quit")
+
+var flagExitRows = flag.Int("exit-rows", 0, "the number of rows around the cursor printed on quit with -on-exit plain (0: the screen height)")
+
+var flagWrapMessage = flag.Bool("wrap-message", false, "wrap the long messages on the status line instead of truncating them")
+
+var flagReadSize = flag.Int("read-size", 64*1024, "the size of the blocks to read from the input")
+
+var flagStickyEnd = flag.Bool("sticky-end", false, "keep the cursor at the end of the rows on j and k after $ like vim")
+
+var flagNoCache = flag.Bool("no-cache", false, "redraw every row on every key for debugging the screen")
+
+var flagAltScreen = flag.Bool("alt-screen", false, "use the alternate screen and restore the terminal contents on quit (-on-exit is ignored)")
+
+var flagDumb = flag.Bool("dumb", false, "print the screens without any escape sequences (default when TERM=dumb)")
+
+var flagTool = flag.String("tool", "", "the command X runs with {file} and {offset} (default: $BINVIEW_TOOL)")
+
+var flagNoConfirm = flag.Bool("no-confirm", false, "quit without the confirmation unless there are unsaved edits")
+
+var flagVersion = flag.Bool("version", false, "print the version and quit")
+
+var flagWatch = flag.Bool("watch", false, "reload the file when it is rewritten")
+
+var flagBase = flag.String("base", "", "the baseline file to highlight the bytes different from it")
+
+var flagFields = flag.String("fields", "", "the fields of the records shown by J like magic@0:s4,size@4:u32le,flags@8:x2")
+
+var flagAddressSep = flag.String("address-sep", " ", "the separator between the address and the hex pane")
+
+var flagPaneSep = flag.String("pane-sep", " ", "the separator between the hex pane and the text pane")
+
+var flagPalette = flag.String("palette", "", "the file of 256 colors for the image preview of I (truecolor)")
+
+var flagAutoSave = flag.Int("autosave", 0, "write the edited data to -o FILE (or FILE.binview.autosave) every N seconds")
+
+var flagReserveBottom = flag.Int("reserve-bottom", 0, "the number of lines at the bottom of the terminal not to draw into")
+
+var flagCursorLog = flag.String("cursor-log", "", "the file (or fifo) to write the offset and the bytes around the cursor as JSON whenever the cursor moves")
+
+var flagEdit = flag.Bool("edit", false, "start in the edit mode overwriting the bytes with the typed hex digits")
+
+var flagInterleave = flag.Bool("interleave", false, "show each group of -group bytes followed by its text instead of the hex and text panes")
+
+var flagSkipErrors = flag.Bool("skip-errors", false, "continue after the read errors showing the unreadable blocks as ?? (for the seekable files)")
+
+var flagRaw = flag.Bool("raw", false, "show gzip-compressed files as they are instead of decompressing them")
+
+var flagMode = flag.String("mode", "hex", "the display mode: hex, char (escaped characters like od -c) or int8 (signed decimals)")
+
+var flagWidth = flag.String("width", "16", "the number of bytes per row (auto: as many as the terminal shows)")
+
+var flagScrollOff = flag.Int("scrolloff", 0, "the minimal number of rows to keep above and below the cursor")
+
 func main() {
-	if err := mains(os.Args[1:]); err != nil {
+	flag.Parse()
+	if *flagVersion {
+		printVersion(os.Stdout)
+		return
+	}
+	if err := mains(flag.Args()); err != nil {
 		fmt.Fprintln(os.Stderr, err.Error())
 		os.Exit(1)
 	}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Marks are the offsets named with 'a' to 'z'
+type Marks map[string]int
+
+func isMarkName(s string) bool {
+	return len(s) == 1 && 'a' <= s[0] && s[0] <= 'z'
+}
+
+func (m Marks) Get(name string) (int, error) {
+	if !isMarkName(name) {
+		return 0, fmt.Errorf("%q: invalid mark name", name)
+	}
+	offset, ok := m[name]
+	if !ok {
+		return 0, fmt.Errorf("mark '%s is not set", name)
+	}
+	return offset, nil
+}
+
+// Distance reports the distance from the mark `from` to the mark `to`.
+func (m Marks) Distance(from, to string) (string, error) {
+	start, err := m.Get(from)
+	if err != nil {
+		return "", err
+	}
+	end, err := m.Get(to)
+	if err != nil {
+		return "", err
+	}
+	diff := end - start
+	sign := "+"
+	if diff < 0 {
+		sign = "-"
+		diff = -diff
+	}
+	return fmt.Sprintf("'%s(0x%08X) to '%s(0x%08X): %s0x%X (%s%d) bytes",
+		from, homeAddress+int64(start), to, homeAddress+int64(end), sign, diff, sign, diff), nil
+}
+
+// Names returns the names of the marks set in the alphabetical order.
+func (m Marks) Names() []string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// listItem describes the mark for the list of K with the bytes there.
+func (m Marks) listItem(b *Buffer, name string) (string, error) {
+	offset := m[name]
+	if err := b.ReadUntil((offset + PEEK_SIZE - 1) / lineSize); err != nil {
+		return "", err
+	}
+	end := offset + PEEK_SIZE
+	if end > b.Len() {
+		end = b.Len()
+	}
+	var preview []byte
+	if offset < end {
+		preview = copyRange(b, offset, end-1)
+	}
+	return fmt.Sprintf("'%s %08X % X", name, homeAddress+int64(offset), preview), nil
+}
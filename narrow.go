@@ -0,0 +1,72 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/mattn/go-runewidth"
+)
+
+const TOO_SMALL_MESSAGE = "terminal too small"
+
+// hideTextPane and hideHexPane are set by fitPanes for the narrow screen.
+// The word pane is hidden with the text pane.
+var hideTextPane, hideHexPane = false, false
+
+func rowWidth() int {
+	var buffer strings.Builder
+	draw(&buffer, 0, -1, -1, make([]byte, lineSize), nil)
+	return runewidth.StringWidth(stripEscapes(buffer.String()))
+}
+
+// fitPanes hides the text pane and then the hex pane until the row fits
+// in w columns. It returns false when even the address does not fit.
+func fitPanes(w int) bool {
+	hideTextPane, hideHexPane = false, false
+	if rowWidth() <= w {
+		return true
+	}
+	hideTextPane = true
+	if rowWidth() <= w {
+		return true
+	}
+	hideHexPane = true
+	return rowWidth() <= w
+}
+
+// fitLineSize returns the most bytes per row which fit in w columns with
+// all the panes. The multiple of -group is preferred.
+func fitLineSize(w int) int {
+	save := lineSize
+	defer func() { lineSize = save }()
+	hideTextPane, hideHexPane = false, false
+	fit := MIN_LINE_SIZE
+	for lineSize = MAX_LINE_SIZE; lineSize > MIN_LINE_SIZE; lineSize-- {
+		if rowWidth() <= w {
+			if fit == MIN_LINE_SIZE {
+				fit = lineSize
+			}
+			if lineSize%*flagGroup == 0 {
+				return lineSize
+			}
+		}
+	}
+	return fit
+}
+
+// wrapText splits s into the lines of w columns at most.
+func wrapText(s string, w int) []string {
+	lines := []string{}
+	var line strings.Builder
+	width := 0
+	for _, c := range s {
+		cw := runewidth.RuneWidth(c)
+		if width+cw > w && width > 0 {
+			lines = append(lines, line.String())
+			line.Reset()
+			width = 0
+		}
+		line.WriteRune(c)
+		width += cw
+	}
+	return append(lines, line.String())
+}
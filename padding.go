@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+)
+
+// PADDING_MIN is the shortest run of the same bytes shown as the padding
+const PADDING_MIN = 16
+
+// PADDING_SCAN_MAX is the longest run scanned not to read the whole of the huge padding
+// at every move of the cursor. The longer ones are told as "≥N bytes".
+const PADDING_SCAN_MAX = 1 << 20
+
+// paddingRun is the last run found by padding, which is reused while
+// the cursor stays in it and the data is not edited.
+var paddingRun struct {
+	start, end int
+	changes    int
+	valid      bool
+	// truncated tells the scan stopped at PADDING_SCAN_MAX
+	truncated bool
+}
+
+// padding returns the hint for the status line when the byte at offset is
+// in the run of the same bytes not shorter than PADDING_MIN.
+// changes is Undo.Changes to tell the edits.
+func padding(b *Buffer, offset, changes int) string {
+	r := &paddingRun
+	if !r.valid || r.changes != changes || offset < r.start || offset > r.end {
+		c := b.ByteAt(offset)
+		truncated := false
+		start := offset
+		for start > 0 && b.ByteAt(start-1) == c {
+			if offset-start+1 >= PADDING_SCAN_MAX {
+				truncated = true
+				break
+			}
+			start--
+		}
+		end := offset
+		for {
+			if end-start+1 >= PADDING_SCAN_MAX {
+				truncated = true
+				break
+			}
+			if err := b.ReadUntil((end + 1) / lineSize); err != nil {
+				return ""
+			}
+			if end+1 >= b.Len() || b.ByteAt(end+1) != c {
+				break
+			}
+			end++
+		}
+		r.start, r.end, r.changes, r.valid, r.truncated = start, end, changes, true, truncated
+	}
+	if r.end-r.start+1 < PADDING_MIN {
+		return ""
+	}
+	if r.truncated {
+		return fmt.Sprintf(" (in 0x%02X padding, ≥%d bytes)", b.ByteAt(offset), r.end-r.start+1)
+	}
+	return fmt.Sprintf(" (in 0x%02X padding, %d bytes from 0x%08X)",
+		b.ByteAt(offset), r.end-r.start+1, homeAddress+int64(r.start))
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+const PEEK_SIZE = 8
+
+// pinnedOffset is the offset whose peek stays above the status line.
+// It is -1 when nothing is pinned.
+var pinnedOffset = -1
+
+// peekAt describes the bytes at offset without moving the cursor.
+func peekAt(b *Buffer, offset int) (string, error) {
+	if err := b.ReadUntil((offset + PEEK_SIZE - 1) / lineSize); err != nil {
+		return "", err
+	}
+	if offset < 0 || offset >= b.Len() {
+		return "", fmt.Errorf("0x%08X: out of the data", homeAddress+int64(offset))
+	}
+	size := PEEK_SIZE
+	if offset+size > b.Len() {
+		size = b.Len() - offset
+	}
+	var hex strings.Builder
+	var value uint64
+	for i := 0; i < size; i++ {
+		c := b.ByteAt(offset + i)
+		fmt.Fprintf(&hex, " %02X", c)
+		value |= uint64(c) << (8 * uint(i))
+	}
+	return fmt.Sprintf("peek 0x%08X:%s (0x%X as little endian)",
+		homeAddress+int64(offset), hex.String(), value), nil
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// readPointer reads the little endian value of size bytes at offset.
+func readPointer(b *Buffer, offset, size int) (uint64, error) {
+	if err := b.ReadUntil((offset + size - 1) / lineSize); err != nil {
+		return 0, err
+	}
+	if offset+size > b.Len() {
+		return 0, errors.New("the pointer is out of the data")
+	}
+	var value uint64
+	for i := size - 1; i >= 0; i-- {
+		value = value<<8 | uint64(b.ByteAt(offset+i))
+	}
+	return value, nil
+}
+
+func isDigitOf(c byte, base int) bool {
+	_, err := strconv.ParseUint(string(rune(c)), base, 8)
+	return err == nil
+}
+
+// readTextNumber reads the run of the digits which the byte at offset is in
+// as the number in base like the fields of the tar header. The spaces before
+// the digits are skipped.
+func readTextNumber(b *Buffer, offset, base int) (uint64, error) {
+	if err := b.ReadUntil(offset / lineSize); err != nil {
+		return 0, err
+	}
+	for offset < b.Len() && b.ByteAt(offset) == ' ' {
+		offset++
+		if err := b.ReadUntil(offset / lineSize); err != nil {
+			return 0, err
+		}
+	}
+	if offset >= b.Len() || !isDigitOf(b.ByteAt(offset), base) {
+		return 0, fmt.Errorf("not on the digits of base %d", base)
+	}
+	start := offset
+	for start > 0 && isDigitOf(b.ByteAt(start-1), base) {
+		start--
+	}
+	var digits []byte
+	for i := start; ; i++ {
+		if err := b.ReadUntil(i / lineSize); err != nil {
+			return 0, err
+		}
+		if i >= b.Len() || !isDigitOf(b.ByteAt(i), base) {
+			break
+		}
+		digits = append(digits, b.ByteAt(i))
+	}
+	value, err := strconv.ParseUint(string(digits), base, 63)
+	if err != nil {
+		return 0, fmt.Errorf("%s: too large", digits)
+	}
+	return value, nil
+}
+
+// pointerTarget returns the offset the value at offset points to.
+// mode is "a" for the absolute address, "o" for the offset from the top of
+// the data and "s" for the signed distance from the pointer's own address.
+// "d", "8" and "x" read the text number in decimal, octal and hex by
+// readTextNumber as the offset from the top of the data.
+func pointerTarget(b *Buffer, offset, size int, mode string) (int, error) {
+	switch mode {
+	case "d", "8", "x":
+		base := map[string]int{"d": 10, "8": 8, "x": 16}[mode]
+		value, err := readTextNumber(b, offset, base)
+		if err != nil {
+			return 0, err
+		}
+		return intOffset(int64(value))
+	}
+	value, err := readPointer(b, offset, size)
+	if err != nil {
+		return 0, err
+	}
+	switch mode {
+	case "a":
+		return intOffset(int64(value) - homeAddress)
+	case "o":
+		return intOffset(int64(value))
+	case "s":
+		shift := uint(64 - size*8)
+		return intOffset(int64(offset) + int64(value<<shift)>>shift)
+	}
+	return 0, fmt.Errorf("%q: unknown mode", mode)
+}
+
+// intOffset converts the offset calculated in int64 to int,
+// failing instead of wrapping around where int is 32 bits.
+func intOffset(offset int64) (int, error) {
+	if int64(int(offset)) != offset {
+		return 0, fmt.Errorf("0x%08X: out of the data", homeAddress+offset)
+	}
+	return int(offset), nil
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+type processMemory struct {
+	*io.SectionReader
+	fd *os.File
+}
+
+func (p *processMemory) Close() error {
+	return p.fd.Close()
+}
+
+// findMapping returns the end of the region in /proc/PID/maps
+// which contains address.
+func findMapping(pid int, address int64) (int64, error) {
+	fd, err := os.Open(fmt.Sprintf("/proc/%d/maps", pid))
+	if err != nil {
+		return 0, err
+	}
+	defer fd.Close()
+
+	sc := bufio.NewScanner(fd)
+	for sc.Scan() {
+		field := strings.Fields(sc.Text())
+		if len(field) < 1 {
+			continue
+		}
+		r := strings.SplitN(field[0], "-", 2)
+		if len(r) < 2 {
+			continue
+		}
+		start, err1 := strconv.ParseUint(r[0], 16, 64)
+		end, err2 := strconv.ParseUint(r[1], 16, 64)
+		if err1 != nil || err2 != nil {
+			continue
+		}
+		if int64(start) <= address && address < int64(end) {
+			return int64(end), nil
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return 0, err
+	}
+	return 0, fmt.Errorf("0x%X: not mapped in the process %d", address, pid)
+}
+
+// openProcessMemory opens the memory of the process from address via
+// /proc/PID/mem. When length <= 0, it reads until the end of the mapping.
+func openProcessMemory(pid int, address, length int64) (io.ReadCloser, error) {
+	if length <= 0 {
+		end, err := findMapping(pid, address)
+		if err != nil {
+			return nil, err
+		}
+		length = end - address
+	}
+	fd, err := os.Open(fmt.Sprintf("/proc/%d/mem", pid))
+	if err != nil {
+		return nil, err
+	}
+	return &processMemory{
+		SectionReader: io.NewSectionReader(fd, address, length),
+		fd:            fd,
+	}, nil
+}
@@ -0,0 +1,13 @@
+//go:build !linux
+// +build !linux
+
+package main
+
+import (
+	"errors"
+	"io"
+)
+
+func openProcessMemory(pid int, address, length int64) (io.ReadCloser, error) {
+	return nil, errors.New("-pid: supported only on Linux")
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Field is one field of the record for the record list of J.
+type Field struct {
+	Label  string
+	Offset int
+	// Type is "s" (text), "x" (hex) or the integer type of parseIntType
+	Type string
+	Size int
+	// signed and bigEndian are for the integer types
+	signed    bool
+	bigEndian bool
+}
+
+// parseFields parses the fields like "magic@0:s4,size@4:u32le,flags@8:x2".
+// sN is the text and xN is the hex of N bytes.
+func parseFields(spec string) ([]Field, error) {
+	fields := []Field{}
+	for _, item := range strings.Split(spec, ",") {
+		at := strings.IndexByte(item, '@')
+		colon := strings.LastIndexByte(item, ':')
+		if at < 0 || colon < at {
+			return nil, fmt.Errorf("%q: not LABEL@OFFSET:TYPE", item)
+		}
+		f := Field{Label: item[:at], Type: item[colon+1:]}
+		offset, err := strconv.ParseUint(item[at+1:colon], 0, 31)
+		if err != nil {
+			return nil, fmt.Errorf("%q: %s: not an offset", item, item[at+1:colon])
+		}
+		f.Offset = int(offset)
+		signed, bits, bigEndian, ok, err := parseIntType(f.Type)
+		if err != nil {
+			return nil, fmt.Errorf("%q: %w", item, err)
+		}
+		if ok {
+			f.Size = bits / 8
+			f.signed = signed
+			f.bigEndian = bigEndian
+		} else if len(f.Type) >= 2 && (f.Type[0] == 's' || f.Type[0] == 'x') {
+			size, err := strconv.Atoi(f.Type[1:])
+			if err != nil || size <= 0 {
+				return nil, fmt.Errorf("%q: %s: not the size", item, f.Type[1:])
+			}
+			f.Size = size
+			f.Type = f.Type[:1]
+		} else {
+			return nil, fmt.Errorf("%q: %s: unknown type (sN, xN, u32le, i16be ...)", item, f.Type)
+		}
+		fields = append(fields, f)
+	}
+	return fields, nil
+}
+
+// decode returns the value of the field of the record at start.
+func (f *Field) decode(b *Buffer, start int) string {
+	offset := start + f.Offset
+	if offset+f.Size > b.Len() {
+		return "--"
+	}
+	data := copyRange(b, offset, offset+f.Size-1)
+	switch f.Type {
+	case "s":
+		var text strings.Builder
+		for _, c := range data {
+			if isPrintable(c) {
+				text.WriteByte(c)
+			} else {
+				text.WriteByte('.')
+			}
+		}
+		return text.String()
+	case "x":
+		return fmt.Sprintf("% X", data)
+	}
+	var value uint64
+	for i := range data {
+		if f.bigEndian {
+			value = value<<8 | uint64(data[i])
+		} else {
+			value = value<<8 | uint64(data[len(data)-1-i])
+		}
+	}
+	if f.signed {
+		shift := uint(64 - f.Size*8)
+		return strconv.FormatInt(int64(value<<shift)>>shift, 10)
+	}
+	return strconv.FormatUint(value, 10)
+}
+
+// recordList returns the offsets of the records of -record after -header
+// and one summary line for each record.
+func recordList(b *Buffer, fields []Field) ([]int, []string) {
+	b.ReadAll()
+	offsets := []int{}
+	items := []string{}
+	for start, i := *flagHeader, 0; start < b.Len(); start, i = start+*flagRecord, i+1 {
+		var line strings.Builder
+		fmt.Fprintf(&line, "#%-5d %08X", i, homeAddress+int64(start))
+		for _, f := range fields {
+			fmt.Fprintf(&line, " %s=%s", f.Label, f.decode(b, start))
+		}
+		offsets = append(offsets, start)
+		items = append(items, line.String())
+	}
+	return offsets, items
+}
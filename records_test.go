@@ -0,0 +1,26 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestParseFields(t *testing.T) {
+	fields, err := parseFields("magic@0:s4,size@4:u32be,flags@0x8:x2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fields) != 3 {
+		t.Fatalf("%d fields (expected 3)", len(fields))
+	}
+	if f := fields[1]; f.Label != "size" || f.Offset != 4 || f.Size != 4 || !f.bigEndian {
+		t.Fatalf("size: %+v", f)
+	}
+	if f := fields[2]; f.Offset != 8 || f.Type != "x" || f.Size != 2 {
+		t.Fatalf("flags: %+v", f)
+	}
+	for _, spec := range []string{"x@0:be", "x@0:le", "x@0:u24", "x@0:s0", "x:u8", "x@y:u8"} {
+		if _, err := parseFields(spec); err == nil {
+			t.Fatalf("%s: no error", spec)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// SCRIPT_WIDTH and SCRIPT_HEIGHT are the screen size for the scripted keys
+// unless -cols and -rows are given.
+const (
+	SCRIPT_WIDTH  = 80
+	SCRIPT_HEIGHT = 25
+)
+
+// scriptedKeys are the keys given with the environment variable
+// BINVIEW_KEYS to test without the terminal. They are used instead of
+// the terminal when not nil.
+var scriptedKeys []string
+
+var errEndOfKeys = errors.New("end of the scripted keys")
+
+var keyNames = map[string]string{
+	"CR":    "\r",
+	"Esc":   _KEY_ESC,
+	"Space": " ",
+	"BS":    "\b",
+	"Up":    _KEY_UP,
+	"Down":  _KEY_DOWN,
+	"Left":  _KEY_LEFT,
+	"Right": _KEY_RIGHT,
+	"Del":   _KEY_DEL,
+	"F2":    _KEY_F2,
+	"lt":    "<",
+}
+
+// parseKeys splits the script like "jjl/0A<CR>n<C-v>" into the keys.
+// The white spaces are ignored. <Space> and <lt> are a space and <.
+func parseKeys(s string) ([]string, error) {
+	keys := []string{}
+	for len(s) > 0 {
+		if s[0] == '<' {
+			end := strings.IndexByte(s, '>')
+			if end < 0 {
+				return nil, fmt.Errorf("%s: no closing >", s)
+			}
+			name := s[1:end]
+			if key, ok := keyNames[name]; ok {
+				keys = append(keys, key)
+			} else if len(name) == 3 && strings.HasPrefix(name, "C-") &&
+				'a' <= name[2] && name[2] <= 'z' {
+				keys = append(keys, string(rune(name[2]-'a'+1)))
+			} else {
+				return nil, fmt.Errorf("<%s>: unknown key", name)
+			}
+			s = s[end+1:]
+			continue
+		}
+		r := []rune(s)[0]
+		if r != ' ' && r != '\t' && r != '\n' && r != '\r' {
+			keys = append(keys, string(r))
+		}
+		s = s[len(string(r)):]
+	}
+	return keys, nil
+}
+
+func nextScriptedKey() (string, error) {
+	if len(scriptedKeys) <= 0 {
+		return "", errEndOfKeys
+	}
+	key := scriptedKeys[0]
+	scriptedKeys = scriptedKeys[1:]
+	return key, nil
+}
+
+// scriptedLine is getline for the scripted keys: the keys until <CR>
+// make the line and <Esc> cancels it.
+func scriptedLine(out io.Writer, prompt, defaultStr string) (string, error) {
+	var line strings.Builder
+	line.WriteString(defaultStr)
+	for {
+		key, err := nextScriptedKey()
+		if err != nil {
+			return "", err
+		}
+		switch key {
+		case "\r":
+			fmt.Fprintf(out, "\r%s%s%s", prompt, line.String(), ERASE_LINE)
+			return line.String(), nil
+		case _KEY_ESC:
+			return "", errors.New("canceled")
+		case "\x15": // Ctrl-U
+			line.Reset()
+		default:
+			line.WriteString(key)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseKeys(t *testing.T) {
+	keys, err := parseKeys("jj /0A<CR><Esc><C-v><lt><Space>")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	expect := []string{"j", "j", "/", "0", "A", "\r", "\x1B", "\x16", "<", " "}
+	if !reflect.DeepEqual(keys, expect) {
+		t.Fatalf("%q != %q", keys, expect)
+	}
+	if _, err := parseKeys("<Foo>"); err == nil {
+		t.Fatal("the unknown key was accepted")
+	}
+}
+
+// runScript runs binview for the data of size bytes 0x00, 0x01, ...
+// with BINVIEW_KEYS and returns the frames printed.
+func runScript(t *testing.T, keys string, size int) string {
+	dir, err := ioutil.TempDir("", "binview")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer os.RemoveAll(dir)
+
+	fname := filepath.Join(dir, "data.bin")
+	data := make([]byte, size)
+	for i := range data {
+		data[i] = byte(i)
+	}
+	if err := ioutil.WriteFile(fname, data, 0666); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	os.Setenv("BINVIEW_KEYS", keys)
+	defer os.Unsetenv("BINVIEW_KEYS")
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	output := make(chan []byte)
+	go func() {
+		bin, _ := ioutil.ReadAll(r)
+		output <- bin
+	}()
+	err = mains([]string{fname})
+	os.Stdout = stdout
+	w.Close()
+	frames := string(<-output)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	return frames
+}
+
+// lastFrame returns the rows of the last frame between the last two status lines.
+func lastFrame(t *testing.T, frames string) []string {
+	lines := strings.Split(frames, "\r\n")
+	var status []int
+	for i, line := range lines {
+		if strings.HasPrefix(line, " (") {
+			status = append(status, i)
+		}
+	}
+	if len(status) < 2 {
+		t.Fatalf("no frame in %q", frames)
+	}
+	return lines[status[len(status)-2]+1 : status[len(status)-1]+1]
+}
+
+func TestScriptedKeys(t *testing.T) {
+	frame := strings.Join(lastFrame(t, runScript(t, "jl", 64)), "\n")
+	if !strings.Contains(frame, "00000010 10 11 12 13 14 15 16 17 18 19 1A 1B 1C 1D 1E 1F") {
+		t.Fatalf("the second row is not shown: %q", frame)
+	}
+	if !strings.Contains(frame, " (00000011):0x11=17") {
+		t.Fatalf("the status line does not show the cursor at 0x11: %q", frame)
+	}
+}
+
+func TestScriptedKeysPinned(t *testing.T) {
+	defer func(rows int) {
+		*flagRows = rows
+		pinnedOffset = -1
+	}(*flagRows)
+	*flagRows = 8
+
+	frame := lastFrame(t, runScript(t, "fjjjjjjj", 256))
+	cursorRow := false
+	for _, line := range frame {
+		if strings.HasPrefix(line, "00000070 ") {
+			cursorRow = true
+		}
+	}
+	if !cursorRow {
+		t.Fatalf("the cursor row 0x70 is not drawn while pinned: %q", frame)
+	}
+	if last := frame[len(frame)-1]; !strings.HasPrefix(last, " (00000070)") {
+		t.Fatalf("the cursor is not at 0x70: %q", last)
+	}
+}
@@ -0,0 +1,259 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// parseHexBytes converts "89 50 4E 47" or "89504E47" into bytes.
+func parseHexBytes(s string) ([]byte, error) {
+	s = strings.Join(strings.Fields(s), "")
+	if s == "" {
+		return nil, errors.New("empty pattern")
+	}
+	if len(s)%2 != 0 {
+		return nil, fmt.Errorf("%s: odd number of hex digits", s)
+	}
+	result := make([]byte, 0, len(s)/2)
+	for i := 0; i < len(s); i += 2 {
+		n, err := strconv.ParseUint(s[i:i+2], 16, 8)
+		if err != nil {
+			return nil, fmt.Errorf("%s: not hex digits", s[i:i+2])
+		}
+		result = append(result, byte(n))
+	}
+	return result, nil
+}
+
+func matchAt(b *Buffer, offset int, pattern []byte) bool {
+	if offset < 0 || offset+len(pattern) > b.Len() {
+		return false
+	}
+	for i, c := range pattern {
+		if b.ByteAt(offset+i) != c {
+			return false
+		}
+	}
+	return true
+}
+
+// replaceAll replaces every non-overlapping from with to.
+// Both patterns must have the same length.
+func replaceAll(b *Buffer, from, to []byte) []Edit {
+	b.ReadAll()
+	edits := []Edit{}
+	for offset := 0; offset+len(from) <= b.Len(); {
+		if !matchAt(b, offset, from) {
+			offset++
+			continue
+		}
+		old := make([]byte, len(from))
+		copy(old, from)
+		b.Splice(offset, len(to), to)
+		edits = append(edits, Edit{Offset: offset, Old: old, New: to})
+		offset += len(from)
+	}
+	return edits
+}
+
+func getReplacePatterns(out io.Writer) ([]byte, []byte, error) {
+	str, err := getline(out, "replace from(hex)>", "")
+	if err != nil {
+		return nil, nil, err
+	}
+	from, err := parseHexBytes(str)
+	if err != nil {
+		return nil, nil, err
+	}
+	str, err = getline(out, "replace to(hex)>", "")
+	if err != nil {
+		return nil, nil, err
+	}
+	to, err := parseHexBytes(str)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(from) != len(to) {
+		return nil, nil, fmt.Errorf("length differs: %d bytes and %d bytes", len(from), len(to))
+	}
+	return from, to, nil
+}
+
+// lastPattern is the pattern which n and N search again and lastMask is
+// its mask (nil to match every bit).
+var lastPattern, lastMask []byte
+
+// parseIntType parses the integer type like "u32le" or "i16be".
+// The type is u or i, the bits (8, 16, 32 or 64) and le (default) or be.
+// ok is false when typ is not the integer type.
+func parseIntType(typ string) (signed bool, bits int, bigEndian bool, ok bool, err error) {
+	if len(typ) < 2 {
+		return false, 0, false, false, nil
+	}
+	name := typ
+	bigEndian = strings.HasSuffix(typ, "be")
+	typ = strings.TrimSuffix(strings.TrimSuffix(typ, "be"), "le")
+	if len(typ) < 2 {
+		// "be" and "le" alone are hex digits
+		return false, 0, false, false, nil
+	}
+	bits, err = strconv.Atoi(typ[1:])
+	if (typ[0] != 'u' && typ[0] != 'i') || err != nil {
+		return false, 0, false, false, nil
+	}
+	if bits != 8 && bits != 16 && bits != 32 && bits != 64 {
+		return false, 0, false, true, fmt.Errorf("%s: the bits must be 8, 16, 32 or 64", name)
+	}
+	return typ[0] == 'i', bits, bigEndian, true, nil
+}
+
+// parseIntPattern encodes "u32le 1234" or "i16be -5" into the bytes.
+// ok is false when s does not begin with the type of parseIntType.
+func parseIntPattern(s string) ([]byte, bool, error) {
+	fields := strings.Fields(s)
+	if len(fields) != 2 {
+		return nil, false, nil
+	}
+	signed, bits, bigEndian, ok, err := parseIntType(fields[0])
+	if !ok || err != nil {
+		return nil, ok, err
+	}
+	var value uint64
+	if signed {
+		var n int64
+		n, err = strconv.ParseInt(fields[1], 0, bits)
+		value = uint64(n)
+	} else {
+		value, err = strconv.ParseUint(fields[1], 0, bits)
+	}
+	if err != nil {
+		return nil, true, fmt.Errorf("%s: not %s", fields[1], fields[0])
+	}
+	result := make([]byte, bits/8)
+	for i := range result {
+		if bigEndian {
+			result[len(result)-1-i] = byte(value >> (8 * uint(i)))
+		} else {
+			result[i] = byte(value >> (8 * uint(i)))
+		}
+	}
+	return result, true, nil
+}
+
+// parseSearchPattern parses the pattern typed for /.
+// The pattern beginning with a double quotation is a text,
+// the one beginning with the integer type is encoded by parseIntPattern and
+// the others are hex digits which may be followed by "mask=" and
+// the hex digits of the bits to compare like "89 50 mask=FF F0".
+func parseSearchPattern(s string) ([]byte, []byte, error) {
+	if pattern, ok, err := parseIntPattern(s); ok {
+		return pattern, nil, err
+	}
+	if strings.HasPrefix(s, `"`) {
+		text := strings.TrimSuffix(s[1:], `"`)
+		if text == "" {
+			return nil, nil, errors.New("empty pattern")
+		}
+		return []byte(text), nil, nil
+	}
+	var mask []byte
+	if i := strings.Index(s, "mask="); i >= 0 {
+		var err error
+		mask, err = parseHexBytes(s[i+len("mask="):])
+		if err != nil {
+			return nil, nil, fmt.Errorf("mask: %w", err)
+		}
+		s = s[:i]
+	}
+	pattern, err := parseHexBytes(strings.TrimPrefix(strings.TrimSpace(s), "pattern="))
+	if err != nil {
+		return nil, nil, err
+	}
+	if mask != nil && len(mask) != len(pattern) {
+		return nil, nil, fmt.Errorf("length differs: pattern %d bytes and mask %d bytes", len(pattern), len(mask))
+	}
+	return pattern, mask, nil
+}
+
+// matchMaskedAt is matchAt comparing only the bits set in mask.
+func matchMaskedAt(b *Buffer, offset int, pattern, mask []byte) bool {
+	if mask == nil {
+		return matchAt(b, offset, pattern)
+	}
+	if offset < 0 || offset+len(pattern) > b.Len() {
+		return false
+	}
+	for i, c := range pattern {
+		if b.ByteAt(offset+i)&mask[i] != c&mask[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// scopeStart and scopeEnd limit the range of / n N (both inclusive).
+// They are -1 when the whole data is searched.
+var scopeStart, scopeEnd = -1, -1
+
+// searchRange returns the range of the search. end is exclusive.
+func searchRange(b *Buffer) (int, int) {
+	if scopeStart < 0 {
+		return 0, b.Len()
+	}
+	end := scopeEnd + 1
+	if end > b.Len() {
+		end = b.Len()
+	}
+	return scopeStart, end
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// searchForward finds pattern after offset, wrapping to the top of the range.
+func searchForward(b *Buffer, offset int, pattern, mask []byte) (int, bool, bool) {
+	b.ReadAll()
+	start, end := searchRange(b)
+	for i := max(offset+1, start); i+len(pattern) <= end; i++ {
+		if matchMaskedAt(b, i, pattern, mask) {
+			return i, false, true
+		}
+	}
+	for i := start; i <= offset && i+len(pattern) <= end; i++ {
+		if matchMaskedAt(b, i, pattern, mask) {
+			return i, true, true
+		}
+	}
+	return 0, false, false
+}
+
+// searchBackward finds pattern before offset, wrapping to the end of the range.
+func searchBackward(b *Buffer, offset int, pattern, mask []byte) (int, bool, bool) {
+	b.ReadAll()
+	start, end := searchRange(b)
+	for i := min(offset-1, end-len(pattern)); i >= start; i-- {
+		if matchMaskedAt(b, i, pattern, mask) {
+			return i, false, true
+		}
+	}
+	for i := end - len(pattern); i >= max(offset, start); i-- {
+		if matchMaskedAt(b, i, pattern, mask) {
+			return i, true, true
+		}
+	}
+	return 0, false, false
+}
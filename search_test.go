@@ -0,0 +1,100 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestReplaceAll(t *testing.T) {
+	source := []byte("ABCABCxxABCAB")
+	buffer := NewBuffer(bytes.NewReader(source))
+
+	edits := replaceAll(buffer, []byte("ABC"), []byte("xyz"))
+	if len(edits) != 3 {
+		t.Fatalf("replaced %d matches (expected 3)", len(edits))
+	}
+	if result := string(buffer.Bytes()); result != "xyzxyzxxxyzAB" {
+		t.Fatalf("'%s' != 'xyzxyzxxxyzAB'", result)
+	}
+
+	undo := NewUndo()
+	undo.Push(edits...)
+	undo.Pop(buffer)
+	if result := buffer.Bytes(); !bytes.Equal(result, source) {
+		t.Fatalf("'%s' != '%s'", result, source)
+	}
+}
+
+func TestSearchScope(t *testing.T) {
+	buffer := NewBuffer(bytes.NewReader([]byte("AB..AB..AB..AB")))
+	scopeStart, scopeEnd = 4, 10
+	defer func() { scopeStart, scopeEnd = -1, -1 }()
+
+	if found, wrapped, ok := searchForward(buffer, 4, []byte("AB"), nil); !ok || wrapped || found != 8 {
+		t.Fatalf("forward: %d,%v,%v (expected 8,false,true)", found, wrapped, ok)
+	}
+	if found, wrapped, ok := searchForward(buffer, 8, []byte("AB"), nil); !ok || !wrapped || found != 4 {
+		t.Fatalf("forward: %d,%v,%v (expected 4,true,true)", found, wrapped, ok)
+	}
+	if found, wrapped, ok := searchBackward(buffer, 4, []byte("AB"), nil); !ok || !wrapped || found != 8 {
+		t.Fatalf("backward: %d,%v,%v (expected 8,true,true)", found, wrapped, ok)
+	}
+	if _, _, ok := searchForward(buffer, 0, []byte("..AB..AB.."), nil); ok {
+		t.Fatal("found the pattern longer than the scope")
+	}
+}
+
+func TestSearchMask(t *testing.T) {
+	buffer := NewBuffer(bytes.NewReader([]byte{0x00, 0x89, 0x5F, 0x89, 0x53}))
+	pattern, mask, err := parseSearchPattern("pattern=89 50 mask=FF F0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if found, _, ok := searchForward(buffer, 0, pattern, mask); !ok || found != 1 {
+		t.Fatalf("%d,%v (expected 1,true)", found, ok)
+	}
+	if found, _, ok := searchForward(buffer, 1, pattern, mask); !ok || found != 3 {
+		t.Fatalf("%d,%v (expected 3,true)", found, ok)
+	}
+	if _, _, err := parseSearchPattern("89 50 mask=FF"); err == nil {
+		t.Fatal("the mask shorter than the pattern was accepted")
+	}
+}
+
+func TestParseIntPattern(t *testing.T) {
+	for source, expect := range map[string][]byte{
+		"u32le 1234":  {0xD2, 0x04, 0x00, 0x00},
+		"u32 0x1234":  {0x34, 0x12, 0x00, 0x00},
+		"u16be 1234":  {0x04, 0xD2},
+		"i16le -2":    {0xFE, 0xFF},
+		"i8 -1":       {0xFF},
+		"u64be 0x102": {0, 0, 0, 0, 0, 0, 0x01, 0x02},
+	} {
+		result, _, err := parseSearchPattern(source)
+		if err != nil {
+			t.Fatalf("%s: %s", source, err)
+		}
+		if !bytes.Equal(result, expect) {
+			t.Fatalf("%s: % X (expected % X)", source, result, expect)
+		}
+	}
+	for _, source := range []string{"u8 256", "i8 128", "u24 1", "u16 -1"} {
+		if _, _, err := parseSearchPattern(source); err == nil {
+			t.Fatalf("%s: no error", source)
+		}
+	}
+	// hex digits are not integer types
+	for source, expect := range map[string][]byte{
+		"89 50": {0x89, 0x50},
+		"be ef": {0xBE, 0xEF},
+	} {
+		if result, _, err := parseSearchPattern(source); err != nil || !bytes.Equal(result, expect) {
+			t.Fatalf("%s: % X %v", source, result, err)
+		}
+	}
+	// "le" is not hex digits but must not be taken for the type either
+	if _, _, err := parseSearchPattern("le 00"); err == nil || !strings.Contains(err.Error(), "not hex digits") {
+		t.Fatalf("le 00: %v", err)
+	}
+}
@@ -0,0 +1,49 @@
+package main
+
+// SEPARATOR_MARK is the character of the line between the blocks
+const SEPARATOR_MARK = "-"
+
+// separatorBefore reports whether the separator line is drawn above row,
+// that is the row has the end of the header (-header) or a record (-record).
+func separatorBefore(row int) bool {
+	header := *flagHeader
+	record := *flagRecord
+	if row <= 0 || (header <= 0 && record <= 0) {
+		return false
+	}
+	start := row * lineSize
+	end := start + lineSize
+	if header > 0 && start <= header && header < end {
+		return true
+	}
+	if record <= 0 || end <= header {
+		return false
+	}
+	k := 1
+	if start > header {
+		k = (start - header + record - 1) / record
+		if k < 1 {
+			k = 1
+		}
+	}
+	return header+k*record < end
+}
+
+// separatedTop returns the top row not less than min to show the row
+// in h lines including the separator lines.
+func separatedTop(min, row, h int) int {
+	top := min
+	for top < row {
+		lines := row - top + 1
+		for r := top + 1; r <= row; r++ {
+			if separatorBefore(r) {
+				lines++
+			}
+		}
+		if lines <= h {
+			break
+		}
+		top++
+	}
+	return top
+}
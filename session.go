@@ -0,0 +1,121 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+)
+
+type SessionHighlight struct {
+	Pattern string `json:"pattern"`
+	Enabled bool   `json:"enabled"`
+}
+
+// Session is the state of the view saved to the file given with -session
+type Session struct {
+	// Base and Length are the address of the top of the data and -length,
+	// which the offsets below are counted in.
+	Base            int64              `json:"base,omitempty"`
+	Length          int64              `json:"length,omitempty"`
+	Offset          int                `json:"offset"`
+	StartRow        int                `json:"start_row"`
+	FrozenRow       int                `json:"frozen_row"`
+	Marks           Marks              `json:"marks,omitempty"`
+	Highlights      []SessionHighlight `json:"highlights,omitempty"`
+	Width           int                `json:"width"`
+	Charset         string             `json:"charset,omitempty"`
+	WordPane        int                `json:"word_pane"`
+	Stride          int                `json:"stride"`
+	RelativeAddress bool               `json:"relative_address"`
+	DecimalAddress  bool               `json:"decimal_address"`
+	EndAddress      bool               `json:"end_address"`
+	RowNumber       bool               `json:"row_number"`
+	CompareAbove    bool               `json:"compare_above"`
+	ByteClassColor  bool               `json:"byte_class_color"`
+}
+
+func LoadSession(fname string) (*Session, error) {
+	bin, err := ioutil.ReadFile(fname)
+	if err != nil {
+		return nil, err
+	}
+	var s Session
+	if err := json.Unmarshal(bin, &s); err != nil {
+		return nil, fmt.Errorf("%s: %w", fname, err)
+	}
+	return &s, nil
+}
+
+func (s *Session) Save(fname string) error {
+	bin, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(fname, bin, 0666)
+}
+
+// Restore applies the settings kept as the package variables.
+func (s *Session) Restore(b *Buffer) error {
+	if s.Base != homeAddress || s.Length != *flagLength {
+		return fmt.Errorf("saved for the data from 0x%08X with -length %d, not from 0x%08X with -length %d",
+			s.Base, s.Length, homeAddress, *flagLength)
+	}
+	if s.Width != 0 {
+		if s.Width < MIN_LINE_SIZE || s.Width > MAX_LINE_SIZE {
+			return fmt.Errorf("width: %d: must be from %d to %d", s.Width, MIN_LINE_SIZE, MAX_LINE_SIZE)
+		}
+		b.SetLineSize(s.Width)
+	}
+	switch s.WordPane {
+	case 0, 2, 4, 8:
+		wordPaneSize = s.WordPane
+	default:
+		return fmt.Errorf("word_pane: %d: must be 0, 2, 4 or 8", s.WordPane)
+	}
+	highlights = highlights[:0]
+	for _, h := range s.Highlights {
+		pattern, err := parseHexBytes(h.Pattern)
+		if err != nil {
+			return err
+		}
+		n, err := addHighlight(pattern)
+		if err != nil {
+			return err
+		}
+		highlights[n-1].Enabled = h.Enabled
+	}
+	if s.Charset != "" {
+		if err := setCharset(s.Charset); err != nil {
+			return err
+		}
+	}
+	relativeAddress = s.RelativeAddress
+	decimalAddress = s.DecimalAddress
+	endAddress = s.EndAddress
+	showRowNumber = s.RowNumber
+	compareAbove = s.CompareAbove
+	byteClassColor = s.ByteClassColor
+	return nil
+}
+
+// Store copies the settings kept as the package variables.
+func (s *Session) Store() {
+	s.Base = homeAddress
+	s.Length = *flagLength
+	s.Width = lineSize
+	s.WordPane = wordPaneSize
+	s.Charset = charset
+	s.Highlights = s.Highlights[:0]
+	for _, h := range highlights {
+		s.Highlights = append(s.Highlights, SessionHighlight{
+			Pattern: fmt.Sprintf("% X", h.Pattern),
+			Enabled: h.Enabled,
+		})
+	}
+	s.RelativeAddress = relativeAddress
+	s.DecimalAddress = decimalAddress
+	s.EndAddress = endAddress
+	s.RowNumber = showRowNumber
+	s.CompareAbove = compareAbove
+	s.ByteClassColor = byteClassColor
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/mattn/go-runewidth"
+)
+
+// parseSetCommand splits ":set NAME=VALUE" into NAME and VALUE.
+// "set NAME" and "set noNAME" turn on and off the boolean option.
+func parseSetCommand(line string) (string, string, error) {
+	field := strings.Fields(line)
+	if len(field) < 1 {
+		return "", "", errors.New("empty command")
+	}
+	if field[0] != "set" && field[0] != "se" {
+		return "", "", fmt.Errorf("%s: unknown command (only set is supported)", field[0])
+	}
+	if len(field) != 2 {
+		return "", "", errors.New("usage: set NAME=VALUE")
+	}
+	if i := strings.IndexByte(field[1], '='); i >= 0 {
+		return field[1][:i], field[1][i+1:], nil
+	}
+	if strings.HasPrefix(field[1], "no") {
+		return field[1][2:], "false", nil
+	}
+	return field[1], "true", nil
+}
+
+func parseSize(name, value string, min int) (int, error) {
+	n, err := strconv.ParseInt(value, 0, 0)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %s: not a number", name, value)
+	}
+	if int(n) < min {
+		return 0, fmt.Errorf("%s: %d: must be %d or more", name, n, min)
+	}
+	return int(n), nil
+}
+
+// setOption changes the display option except the width which moves
+// the cursor. It returns the message for the status line.
+func setOption(name, value string) (string, error) {
+	switch name {
+	case "charset":
+		if err := setCharset(value); err != nil {
+			return "", err
+		}
+		return "charset: " + charset, nil
+	case "mode":
+		if value != "hex" && value != "char" && value != "int8" {
+			return "", fmt.Errorf("mode: %s: must be hex, char or int8", value)
+		}
+		*flagMode = value
+		return "mode: " + value, nil
+	case "word-pane":
+		n, err := parseSize(name, value, 0)
+		if err != nil {
+			return "", err
+		}
+		if n != 0 && n != 2 && n != 4 && n != 8 {
+			return "", fmt.Errorf("word-pane: %d: must be 0, 2, 4 or 8", n)
+		}
+		wordPaneSize = n
+		return fmt.Sprintf("word pane: %d bytes per word", n), nil
+	case "group":
+		n, err := parseSize(name, value, 1)
+		if err != nil {
+			return "", err
+		}
+		*flagGroup = n
+		return fmt.Sprintf("group: %d bytes", n), nil
+	case "group-sep":
+		if runewidth.StringWidth(value) != 1 {
+			return "", fmt.Errorf("group-sep: %q: must be one character", value)
+		}
+		*flagGroupSep = value
+		return fmt.Sprintf("group-sep: %q", value), nil
+	case "header", "record", "scrolloff":
+		n, err := parseSize(name, value, 0)
+		if err != nil {
+			return "", err
+		}
+		switch name {
+		case "header":
+			*flagHeader = n
+		case "record":
+			*flagRecord = n
+		default:
+			*flagScrollOff = n
+		}
+		return fmt.Sprintf("%s: %d", name, n), nil
+	case "squeeze", "number", "class", "interleave":
+		on, err := strconv.ParseBool(value)
+		if err != nil {
+			return "", fmt.Errorf("%s: %s: must be true or false", name, value)
+		}
+		switch name {
+		case "squeeze":
+			*flagSqueeze = on
+		case "number":
+			showRowNumber = on
+		case "interleave":
+			*flagInterleave = on
+		default:
+			byteClassColor = on
+		}
+		return fmt.Sprintf("%s: %t", name, on), nil
+	}
+	return "", fmt.Errorf("%s: unknown option", name)
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+)
+
+// SideCar is the per-file state saved as FILE.binview
+type SideCar struct {
+	Offset int   `json:"offset"`
+	Marks  Marks `json:"marks,omitempty"`
+}
+
+func sideCarName(fname string) string {
+	return fname + ".binview"
+}
+
+func LoadSideCar(fname string) (*SideCar, error) {
+	bin, err := ioutil.ReadFile(sideCarName(fname))
+	if err != nil {
+		return nil, err
+	}
+	var s SideCar
+	if err := json.Unmarshal(bin, &s); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// Save writes the sidecar. Nothing is worth saving at the top of the file without marks,
+// so the old sidecar is removed instead.
+func (s *SideCar) Save(fname string) error {
+	if s.Offset == 0 && len(s.Marks) == 0 {
+		if err := os.Remove(sideCarName(fname)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	bin, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(sideCarName(fname), bin, 0666)
+}
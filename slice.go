@@ -0,0 +1,67 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+)
+
+type sliceReader struct {
+	io.Reader
+	closer io.Closer
+}
+
+func (s *sliceReader) Close() error {
+	return s.closer.Close()
+}
+
+// sliceInput skips offset bytes of r and reads only length bytes after them.
+// When length <= 0, it reads until the end of r.
+func sliceInput(r io.ReadCloser, offset, length int64) (io.ReadCloser, error) {
+	if offset > 0 {
+		n, err := io.CopyN(ioutil.Discard, r, offset)
+		if err == io.EOF {
+			return nil, fmt.Errorf("-offset: the data has only %d bytes", n)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if length <= 0 {
+		return r, nil
+	}
+	return &sliceReader{Reader: io.LimitReader(r, length), closer: r}, nil
+}
+
+// argfOf returns the Argf under r even when it is sliced with -offset or -length.
+func argfOf(r io.Reader) (*Argf, bool) {
+	if s, ok := r.(*sliceReader); ok {
+		argf, ok := s.closer.(*Argf)
+		return argf, ok
+	}
+	argf, ok := r.(*Argf)
+	return argf, ok
+}
+
+// openInput opens the files or the standard input and slices them with -offset and -length.
+// It is used both at the start and on the reload of -watch.
+func openInput(args []string) (io.ReadCloser, error) {
+	pin, err := NewArgf(args)
+	if err != nil {
+		return nil, err
+	}
+	if *flagOffset == 0 && *flagLength <= 0 {
+		return pin, nil
+	}
+	if *flagOffset < 0 {
+		pin.Close()
+		return nil, errors.New("-offset: must not be negative")
+	}
+	sliced, err := sliceInput(pin, *flagOffset, *flagLength)
+	if err != nil {
+		pin.Close()
+		return nil, err
+	}
+	return sliced, nil
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"bytes"
+)
+
+const SQUEEZE_MARK = "*"
+
+// isSameRow is true when the row r is a full row same as the row r-1,
+// which is hidden on the squeeze mode.
+func isSameRow(b *Buffer, r int) bool {
+	return r > 0 && r < b.Count() &&
+		b.WidthAt(r) == lineSize && b.WidthAt(r-1) == lineSize &&
+		bytes.Equal(b.Line(r), b.Line(r-1))
+}
+
+// nextSqueezedRow returns the row the cursor moves to down from row
+// stepping over the hidden rows.
+func nextSqueezedRow(b *Buffer, row int) (int, error) {
+	next := row + 1
+	for {
+		if err := b.ReadUntil(next); err != nil {
+			return row, err
+		}
+		if next >= b.Count() {
+			return b.Count() - 1, nil
+		}
+		if !isSameRow(b, next) {
+			return next, nil
+		}
+		next++
+	}
+}
+
+// prevSqueezedRow returns the row the cursor moves to up from row
+// stepping over the hidden rows.
+func prevSqueezedRow(b *Buffer, row int) int {
+	if row <= 0 {
+		return 0
+	}
+	prev := row - 1
+	for isSameRow(b, prev) {
+		prev--
+	}
+	return prev
+}
+
+// squeezedTop returns the top row to fit the rows from the top to row
+// into h lines on the squeeze mode. It does not return less than min.
+func squeezedTop(b *Buffer, min, row, h int) int {
+	count := 1
+	for top := row - 1; top >= min; top-- {
+		next := top + 1
+		if next != row && isSameRow(b, next) && next+1 < row && isSameRow(b, next+1) {
+			// the row next is hidden and joins the following mark
+		} else {
+			count++
+		}
+		if count > h {
+			return top + 1
+		}
+	}
+	return min
+}
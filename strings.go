@@ -0,0 +1,105 @@
+package main
+
+import (
+	"errors"
+)
+
+// foundStart and foundEnd are the range (both inclusive) highlighted
+// as the result of the last jump. They are -1 when nothing is found.
+var foundStart, foundEnd = -1, -1
+
+func isFound(offset int64) bool {
+	return int64(foundStart) <= offset && offset <= int64(foundEnd)
+}
+
+func isPrintable(c byte) bool {
+	return (0x20 <= c && c <= 0x7E) || c == '\t'
+}
+
+// nextString returns the range of the next run of printable bytes
+// not shorter than min bytes after offset.
+func nextString(b *Buffer, offset, min int) (int, int, bool) {
+	b.ReadAll()
+	size := b.Len()
+	i := offset
+	for i < size && isPrintable(b.ByteAt(i)) {
+		i++
+	}
+	for i < size {
+		if !isPrintable(b.ByteAt(i)) {
+			i++
+			continue
+		}
+		start := i
+		for i < size && isPrintable(b.ByteAt(i)) {
+			i++
+		}
+		if i-start >= min {
+			return start, i - 1, true
+		}
+	}
+	return -1, -1, false
+}
+
+// prevString returns the range of the previous run of printable bytes
+// not shorter than min bytes before offset.
+func prevString(b *Buffer, offset, min int) (int, int, bool) {
+	i := offset
+	for i >= 0 && i < b.Len() && isPrintable(b.ByteAt(i)) {
+		i--
+	}
+	for i >= 0 {
+		if !isPrintable(b.ByteAt(i)) {
+			i--
+			continue
+		}
+		end := i
+		for i >= 0 && isPrintable(b.ByteAt(i)) {
+			i--
+		}
+		if end-i >= min {
+			return i + 1, end, true
+		}
+	}
+	return -1, -1, false
+}
+
+// stringAround returns the range of the run of printable bytes which
+// the byte at offset is in.
+func stringAround(b *Buffer, offset int) (int, int, error) {
+	if offset < 0 || offset >= b.Len() || !isPrintable(b.ByteAt(offset)) {
+		return -1, -1, errors.New("not on a printable string")
+	}
+	start := offset
+	for start > 0 && isPrintable(b.ByteAt(start-1)) {
+		start--
+	}
+	end := offset
+	for {
+		if err := b.ReadUntil((end + 1) / lineSize); err != nil {
+			return -1, -1, err
+		}
+		if end+1 >= b.Len() || !isPrintable(b.ByteAt(end+1)) {
+			return start, end, nil
+		}
+		end++
+	}
+}
+
+type StringRun struct {
+	Start, End int
+}
+
+// allStrings returns all the runs of printable bytes not shorter than min bytes.
+func allStrings(b *Buffer, min int) []StringRun {
+	b.ReadAll()
+	result := []StringRun{}
+	for offset := 0; ; {
+		start, end, ok := nextString(b, offset, min)
+		if !ok {
+			return result
+		}
+		result = append(result, StringRun{Start: start, End: end})
+		offset = end + 1
+	}
+}
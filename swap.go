@@ -0,0 +1,80 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+)
+
+// Journal records the edits into FILE.binview.swp to recover them
+// after binview crashed.
+type Journal struct {
+	name string
+	fd   *os.File
+}
+
+func swapName(fname string) string {
+	return fname + ".binview.swp"
+}
+
+func OpenJournal(fname string) (*Journal, error) {
+	name := swapName(fname)
+	fd, err := os.OpenFile(name, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0666)
+	if err != nil {
+		return nil, err
+	}
+	return &Journal{name: name, fd: fd}, nil
+}
+
+// Write appends one group of edits as one JSON line.
+func (j *Journal) Write(edits []Edit) error {
+	bin, err := json.Marshal(edits)
+	if err != nil {
+		return err
+	}
+	bin = append(bin, '\n')
+	if _, err := j.fd.Write(bin); err != nil {
+		return err
+	}
+	return j.fd.Sync()
+}
+
+// Reset drops the edits which are saved already.
+func (j *Journal) Reset() error {
+	return j.fd.Truncate(0)
+}
+
+func (j *Journal) Remove() error {
+	err := j.fd.Close()
+	os.Remove(j.name)
+	return err
+}
+
+// ReadJournal reads the groups of edits left in FILE.binview.swp
+func ReadJournal(fname string) ([][]Edit, error) {
+	fd, err := os.Open(swapName(fname))
+	if err != nil {
+		return nil, err
+	}
+	defer fd.Close()
+
+	var log [][]Edit
+	sc := bufio.NewScanner(fd)
+	sc.Buffer(make([]byte, 0, 64*1024), 1024*1024*1024)
+	for sc.Scan() {
+		var edits []Edit
+		if err := json.Unmarshal(sc.Bytes(), &edits); err != nil {
+			return log, err
+		}
+		log = append(log, edits)
+	}
+	return log, sc.Err()
+}
+
+func inverseEdits(edits []Edit) []Edit {
+	result := make([]Edit, len(edits))
+	for i, e := range edits {
+		result[len(edits)-1-i] = Edit{Offset: e.Offset, Old: e.New, New: e.Old}
+	}
+	return result
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"fmt"
+)
+
+// swapView shows every pair of bytes in each row swapped without
+// changing the data
+var swapView = false
+
+// swapIndex returns the position in the row shown at i with swapView.
+// The last byte of the row with the odd length is not swapped.
+func swapIndex(i, n int) int {
+	if !swapView || i < 0 || i^1 >= n {
+		return i
+	}
+	return i ^ 1
+}
+
+// swapPairs returns the copy of slice with every pair of bytes swapped.
+func swapPairs(slice []byte) []byte {
+	if slice == nil {
+		return nil
+	}
+	result := make([]byte, len(slice))
+	for i := range slice {
+		if i^1 < len(slice) {
+			result[i] = slice[i^1]
+		} else {
+			result[i] = slice[i]
+		}
+	}
+	return result
+}
+
+// swapSelection swaps every pair of bytes in the selection.
+func swapSelection(b *Buffer) (Edit, string, error) {
+	old := copyRange(b, selectStart, selectEnd)
+	if len(old)%2 != 0 {
+		return Edit{}, "", fmt.Errorf("%d bytes: the selection must be the pairs of bytes", len(old))
+	}
+	new := make([]byte, len(old))
+	for i := 0; i < len(old); i += 2 {
+		new[i], new[i+1] = old[i+1], old[i]
+	}
+	b.Splice(selectStart, len(new), new)
+	return Edit{Offset: selectStart, Old: old, New: new}, fmt.Sprintf("swapped the bytes of %d words", len(new)/2), nil
+}
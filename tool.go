@@ -0,0 +1,36 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+)
+
+func shellQuote(s string) string {
+	if runtime.GOOS == "windows" {
+		return `"` + s + `"`
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// toolCommandLine expands {file} and {offset} in template.
+// The file name is appended when template does not have {file}.
+func toolCommandLine(template, fname string, offset int64) string {
+	if !strings.Contains(template, "{file}") {
+		template += " {file}"
+	}
+	return strings.NewReplacer(
+		"{file}", shellQuote(fname),
+		"{offset}", fmt.Sprintf("%d", offset),
+	).Replace(template)
+}
+
+// runTool runs the external tool on the terminal.
+func runTool(template, fname string, offset int64) error {
+	cmd := shellCommand(toolCommandLine(template, fname, offset))
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+type Edit struct {
+	Offset int    `json:"offset"`
+	Old    []byte `json:"old"`
+	New    []byte `json:"new"`
+}
+
+// Undo keeps groups of edits. One group is restored by one undo.
+type Undo struct {
+	log [][]Edit
+	// Journal records the edits and undos for crash recovery when not nil.
+	// It is opened at the first edit for journalFor given by SetJournal.
+	// Its errors do not cancel the edits, which are done already, and
+	// are returned by Err to show them.
+	Journal    *Journal
+	journalFor string
+	err        error
+	// changes counts the pushes and the pops to tell whether the data changed
+	changes int
+}
+
+func NewUndo() *Undo {
+	return &Undo{log: make([][]Edit, 0, 100)}
+}
+
+func (u *Undo) Push(edits ...Edit) {
+	if len(edits) > 0 {
+		u.changes++
+		u.log = append(u.log, edits)
+		if j := u.journal(); j != nil {
+			u.setErr(j.Write(edits))
+		}
+	}
+}
+
+// SetJournal makes the edits recorded into the journal of fname.
+func (u *Undo) SetJournal(fname string) {
+	u.journalFor = fname
+}
+
+func (u *Undo) journal() *Journal {
+	if u.Journal == nil && u.journalFor != "" {
+		j, err := OpenJournal(u.journalFor)
+		if err != nil {
+			// do not retry on every edit
+			u.journalFor = ""
+			u.setErr(fmt.Errorf("journal: %w", err))
+			return nil
+		}
+		u.Journal = j
+	}
+	return u.Journal
+}
+
+func (u *Undo) setErr(err error) {
+	if err != nil {
+		u.err = err
+	}
+}
+
+// Err returns the last error of the journal once.
+func (u *Undo) Err() error {
+	err := u.err
+	u.err = nil
+	return err
+}
+
+// ResetJournal drops the edits which are saved already into fname.
+// The journal is kept when fname is another file than the one of the journal
+// since its edits are still to be recovered onto that file.
+func (u *Undo) ResetJournal(fname string) {
+	if u.journalFor == "" || !isInputFile(fname, []string{u.journalFor}) {
+		return
+	}
+	if u.Journal != nil {
+		u.setErr(u.Journal.Reset())
+	} else if u.journalFor != "" {
+		// the journal recovered but not opened yet
+		os.Remove(swapName(u.journalFor))
+	}
+}
+
+// RemoveJournal removes the journal on quit.
+func (u *Undo) RemoveJournal() {
+	if u.Journal != nil {
+		u.Journal.Remove()
+	} else if u.journalFor != "" {
+		os.Remove(swapName(u.journalFor))
+	}
+}
+
+func (u *Undo) Len() int {
+	return len(u.log)
+}
+
+func (u *Undo) Changes() int {
+	return u.changes
+}
+
+// Pop restores the last group of edits and returns the offset of its first edit.
+func (u *Undo) Pop(b *Buffer) int {
+	if len(u.log) <= 0 {
+		return -1
+	}
+	tail := len(u.log) - 1
+	edits := u.log[tail]
+	u.log = u.log[:tail]
+	u.changes++
+	if j := u.journal(); j != nil {
+		u.setErr(j.Write(inverseEdits(edits)))
+	}
+	for i := len(edits) - 1; i >= 0; i-- {
+		e := edits[i]
+		b.Splice(e.Offset, len(e.New), e.Old)
+	}
+	return edits[0].Offset
+}
+
+// Redo applies the edits recovered from the journal.
+func (u *Undo) Redo(b *Buffer, edits []Edit) error {
+	b.ReadAll()
+	for _, e := range edits {
+		if e.Offset+len(e.Old) > b.Len() {
+			return fmt.Errorf("offset 0x%X: out of the data", e.Offset)
+		}
+		b.Splice(e.Offset, len(e.Old), e.New)
+	}
+	u.log = append(u.log, edits)
+	return nil
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"io"
+	"os"
+)
+
+const (
+	// UNREADABLE_BLOCK is the unit skipped on the read error with -skip-errors
+	UNREADABLE_BLOCK     = 512
+	UNREADABLE_COLOR_ON  = "\x1B[37;41;1m"
+	UNREADABLE_COLOR_OFF = "\x1B[40;22m"
+)
+
+// unreadable is the list of the ranges which could not be read.
+// The offsets are counted from the top of the input.
+var unreadable []Range
+
+type Range struct {
+	Offset int64
+	Length int64
+}
+
+// isUnreadable tells whether the byte at offset of the buffer is
+// the placeholder of the unreadable range.
+func isUnreadable(offset int64) bool {
+	offset += homeAddress
+	for _, r := range unreadable {
+		if r.Offset <= offset && offset < r.Offset+r.Length {
+			return true
+		}
+	}
+	return false
+}
+
+// skipReader continues reading the file after the read errors by seeking to
+// the next block. The skipped bytes are read as zeros and recorded into
+// unreadable.
+type skipReader struct {
+	*os.File
+	// start is the offset of the file in the whole input
+	start int64
+	size  int64
+	pos   int64
+	skip  int64
+}
+
+func (s *skipReader) Read(data []byte) (int, error) {
+	if s.skip > 0 {
+		n := len(data)
+		if int64(n) > s.skip {
+			n = int(s.skip)
+		}
+		for i := range data[:n] {
+			data[i] = 0
+		}
+		s.skip -= int64(n)
+		s.pos += int64(n)
+		return n, nil
+	}
+	n, err := s.File.Read(data)
+	s.pos += int64(n)
+	if err == nil || err == io.EOF || n > 0 {
+		return n, err
+	}
+	next := (s.pos/UNREADABLE_BLOCK + 1) * UNREADABLE_BLOCK
+	if s.size > 0 && next > s.size {
+		next = s.size
+	}
+	if next <= s.pos {
+		return 0, err
+	}
+	if _, err1 := s.File.Seek(next, io.SeekStart); err1 != nil {
+		// not seekable
+		return 0, err
+	}
+	if last := len(unreadable) - 1; last >= 0 &&
+		unreadable[last].Offset+unreadable[last].Length == s.start+s.pos {
+		unreadable[last].Length += next - s.pos
+	} else {
+		unreadable = append(unreadable, Range{Offset: s.start + s.pos, Length: next - s.pos})
+	}
+	s.skip = next - s.pos
+	return s.Read(data)
+}
@@ -0,0 +1,26 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+func isURL(fname string) bool {
+	return strings.HasPrefix(fname, "http://") || strings.HasPrefix(fname, "https://")
+}
+
+// openURL gets the resource. The body is read only as far as the screen
+// needs like the files.
+func openURL(url string) (io.ReadCloser, int64, error) {
+	resp, err := http.Get(url)
+	if err != nil {
+		return nil, 0, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, 0, fmt.Errorf("%s: %s", url, resp.Status)
+	}
+	return resp.Body, resp.ContentLength, nil
+}
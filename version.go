@@ -0,0 +1,33 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+	"runtime/debug"
+	"strings"
+)
+
+// version is set by the Makefile with -ldflags "-X main.version=..."
+var version = ""
+
+func printVersion(w io.Writer) {
+	v := strings.TrimSpace(version)
+	info, ok := debug.ReadBuildInfo()
+	if v == "" {
+		v = "(devel)"
+		if ok && info.Main.Version != "" {
+			v = info.Main.Version
+		}
+	}
+	fmt.Fprintf(w, "binview %s %s %s/%s\n", v, runtime.Version(), runtime.GOOS, runtime.GOARCH)
+	if !ok {
+		return
+	}
+	for _, m := range info.Deps {
+		if m.Replace != nil {
+			m = m.Replace
+		}
+		fmt.Fprintf(w, "\t%s %s\n", m.Path, m.Version)
+	}
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"fmt"
+)
+
+// selectStart and selectEnd are the range of the visual selection
+// (both inclusive). They are -1 when not in visual mode.
+var selectStart, selectEnd = -1, -1
+
+func isSelected(offset int64) bool {
+	return int64(selectStart) <= offset && offset <= int64(selectEnd)
+}
+
+func setSelection(anchor, cursor int) {
+	if anchor < 0 {
+		selectStart, selectEnd = -1, -1
+	} else if anchor <= cursor {
+		selectStart, selectEnd = anchor, cursor
+	} else {
+		selectStart, selectEnd = cursor, anchor
+	}
+}
+
+func copyRange(b *Buffer, start, end int) []byte {
+	result := make([]byte, 0, end-start+1)
+	for i := start; i <= end; i++ {
+		result = append(result, b.ByteAt(i))
+	}
+	return result
+}
+
+// reverseSelection reverses the byte order of the selected range.
+func reverseSelection(b *Buffer) (Edit, string) {
+	old := copyRange(b, selectStart, selectEnd)
+	new := make([]byte, len(old))
+	for i, c := range old {
+		new[len(new)-1-i] = c
+	}
+	b.Splice(selectStart, len(new), new)
+
+	message := fmt.Sprintf("reversed %d bytes", len(new))
+	if len(new) <= 8 {
+		var value uint64
+		for i := len(new) - 1; i >= 0; i-- {
+			value = value<<8 | uint64(new[i])
+		}
+		message += fmt.Sprintf(": 0x%0*X(%d) as little endian", len(new)*2, value, value)
+	}
+	return Edit{Offset: selectStart, Old: old, New: new}, message
+}
+
+// invertSelection applies the bitwise NOT to every byte of the selection.
+func invertSelection(b *Buffer) (Edit, string) {
+	old := copyRange(b, selectStart, selectEnd)
+	new := make([]byte, len(old))
+	for i, c := range old {
+		new[i] = ^c
+	}
+	b.Splice(selectStart, len(new), new)
+	return Edit{Offset: selectStart, Old: old, New: new}, fmt.Sprintf("inverted %d bytes", len(new))
+}
+
+// duplicateSelection inserts a copy of the selection right after it.
+func duplicateSelection(b *Buffer) (Edit, string) {
+	new := copyRange(b, selectStart, selectEnd)
+	b.Splice(selectEnd+1, 0, new)
+	return Edit{Offset: selectEnd + 1, Old: []byte{}, New: new}, fmt.Sprintf("duplicated %d bytes", len(new))
+}
+
+// fillSelection fills the selection with pattern repeatedly.
+// The last repetition is truncated at the end of the selection.
+func fillSelection(b *Buffer, pattern []byte) (Edit, string) {
+	old := copyRange(b, selectStart, selectEnd)
+	new := make([]byte, len(old))
+	for i := range new {
+		new[i] = pattern[i%len(pattern)]
+	}
+	b.Splice(selectStart, len(new), new)
+	return Edit{Offset: selectStart, Old: old, New: new},
+		fmt.Sprintf("filled %d bytes with % X", len(new), pattern)
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"os"
+	"time"
+)
+
+const WATCH_INTERVAL = time.Second
+
+// Watcher polls the modification time and the size of the file for -watch.
+type Watcher struct {
+	fname   string
+	modTime time.Time
+	size    int64
+}
+
+func NewWatcher(fname string) (*Watcher, error) {
+	w := &Watcher{fname: fname}
+	if _, err := w.Changed(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// Changed reports whether the file is modified since the last call.
+func (w *Watcher) Changed() (bool, error) {
+	stat, err := os.Stat(w.fname)
+	if err != nil {
+		return false, err
+	}
+	if stat.ModTime().Equal(w.modTime) && stat.Size() == w.size {
+		return false, nil
+	}
+	w.modTime = stat.ModTime()
+	w.size = stat.Size()
+	return true, nil
+}
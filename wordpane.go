@@ -0,0 +1,47 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// wordPaneSize is the bytes per word of the second hex pane which shows
+// the row as little endian words. It is 0 when the pane is hidden.
+var wordPaneSize = 0
+
+// drawWords draws the second pane for the row.
+func drawWords(out io.Writer, cursorPos int, slice []byte) {
+	words := (lineSize + wordPaneSize - 1) / wordPaneSize
+	for w := 0; w < words; w++ {
+		start := w * wordPaneSize
+		if start >= len(slice) {
+			io.WriteString(out, strings.Repeat(" ", wordPaneSize*2+1))
+			continue
+		}
+		var value uint64
+		var digits strings.Builder
+		end := start + wordPaneSize
+		if end > len(slice) {
+			end = len(slice)
+			digits.WriteString(strings.Repeat("..", start+wordPaneSize-end))
+		}
+		for i := end - 1; i >= start; i-- {
+			value = value<<8 | uint64(slice[i])
+		}
+		digits.WriteString(fmt.Sprintf("%0*X", (end-start)*2, value))
+
+		var on, off string
+		if start <= cursorPos && cursorPos < start+wordPaneSize {
+			on = CURSOR_COLOR_ON
+			off = CURSOR_COLOR_OFF
+		} else if w%2 == 0 {
+			on = CELL1_COLOR_ON
+			off = CELL1_COLOR_OFF
+		} else {
+			on = CELL2_COLOR_ON
+			off = CELL2_COLOR_OFF
+		}
+		fmt.Fprintf(out, "%s%s%s ", on, digits.String(), off)
+	}
+}